@@ -1,5 +1,9 @@
 package zencoder
 
+import (
+	"context"
+)
+
 type AccountDetails struct {
 	AccountState    string `json:"account_state,omitempty"`
 	Plan            string `json:"plan,omitempty"`
@@ -23,6 +27,11 @@ type CreateAccountResponse struct {
 
 // Create an account
 func (z *Zencoder) CreateAccount(email, password string) (*CreateAccountResponse, error) {
+	return z.CreateAccountContext(context.Background(), email, password)
+}
+
+// Create an account with a Context
+func (z *Zencoder) CreateAccountContext(ctx context.Context, email, password string) (*CreateAccountResponse, error) {
 	request := &CreateAccountRequest{
 		Email:          email,
 		TermsOfService: "1",
@@ -35,7 +44,7 @@ func (z *Zencoder) CreateAccount(email, password string) (*CreateAccountResponse
 
 	var result CreateAccountResponse
 
-	if err := z.post("account", request, &result); err != nil {
+	if err := z.post(ctx, "account", request, &result); err != nil {
 		return nil, err
 	}
 
@@ -44,9 +53,14 @@ func (z *Zencoder) CreateAccount(email, password string) (*CreateAccountResponse
 
 // Get Account Details
 func (z *Zencoder) GetAccount() (*AccountDetails, error) {
+	return z.GetAccountContext(context.Background())
+}
+
+// Get Account Details with a Context
+func (z *Zencoder) GetAccountContext(ctx context.Context) (*AccountDetails, error) {
 	var details AccountDetails
 
-	if err := z.getBody("account", &details); err != nil {
+	if err := z.getBody(ctx, "account", &details); err != nil {
 		return nil, err
 	}
 
@@ -55,10 +69,20 @@ func (z *Zencoder) GetAccount() (*AccountDetails, error) {
 
 // Set Integration Mode
 func (z *Zencoder) SetIntegrationMode() error {
-	return z.putNoContent("account/integration")
+	return z.SetIntegrationModeContext(context.Background())
+}
+
+// Set Integration Mode with a Context
+func (z *Zencoder) SetIntegrationModeContext(ctx context.Context) error {
+	return z.putNoContent(ctx, "account/integration")
 }
 
 // Set Live Mode
 func (z *Zencoder) SetLiveMode() error {
-	return z.putNoContent("account/live")
+	return z.SetLiveModeContext(context.Background())
+}
+
+// Set Live Mode with a Context
+func (z *Zencoder) SetLiveModeContext(ctx context.Context) error {
+	return z.putNoContent(ctx, "account/live")
 }
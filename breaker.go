@@ -0,0 +1,169 @@
+package zencoder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// BreakerState is the state of a circuit
+type BreakerState int
+
+const (
+	BreakerClosed   BreakerState = iota // Requests flow normally
+	BreakerOpen                         // Requests fail fast with ErrCircuitOpen
+	BreakerHalfOpen                     // A limited number of trial requests decide whether to close again
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+
+	return fmt.Sprintf("BreakerState(%d)", int(s))
+}
+
+var ErrCircuitOpen = errors.New("zencoder: circuit breaker is open")
+
+// BreakerSettings configures a Breaker
+type BreakerSettings struct {
+	FailureThreshold int           // Consecutive failures that open the circuit, 5 if zero
+	OpenTimeout      time.Duration // Time spent open before allowing trial requests, 30s if zero
+	HalfOpenRequests int           // Concurrent trial requests allowed while half-open, 1 if zero
+}
+
+// Breaker is a circuit breaker with one circuit per EndpointClass.
+//
+// Connection failures and 5xx responses count as failures; any other response
+// is a success.  After FailureThreshold consecutive failures the circuit
+// opens and requests fail with ErrCircuitOpen without reaching Zencoder.
+// After OpenTimeout it turns half-open: a successful trial request closes it,
+// a failed one opens it again.  A Breaker is safe for concurrent use.
+type Breaker struct {
+	settings BreakerSettings
+	now      func() time.Time
+
+	mu       sync.Mutex
+	circuits map[EndpointClass]*circuit
+}
+
+type circuit struct {
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	trials   int
+}
+
+// NewBreaker returns a Breaker with all circuits closed
+func NewBreaker(settings BreakerSettings) *Breaker {
+	if settings.FailureThreshold <= 0 {
+		settings.FailureThreshold = 5
+	}
+
+	if settings.OpenTimeout <= 0 {
+		settings.OpenTimeout = 30 * time.Second
+	}
+
+	if settings.HalfOpenRequests <= 0 {
+		settings.HalfOpenRequests = 1
+	}
+
+	return &Breaker{
+		settings: settings,
+		now:      time.Now,
+		circuits: make(map[EndpointClass]*circuit),
+	}
+}
+
+// State returns the state of the circuit for an endpoint class
+func (b *Breaker) State(class EndpointClass) BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.circuit(class).state
+}
+
+// States returns the state of every circuit that has seen a request
+func (b *Breaker) States() map[EndpointClass]BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	states := make(map[EndpointClass]BreakerState, len(b.circuits))
+	for class := range b.circuits {
+		states[class] = b.circuit(class).state
+	}
+
+	return states
+}
+
+// circuit returns the circuit for class, moving it from open to half-open
+// once OpenTimeout has passed.  b.mu must be held.
+func (b *Breaker) circuit(class EndpointClass) *circuit {
+	c, ok := b.circuits[class]
+	if !ok {
+		c = &circuit{}
+		b.circuits[class] = c
+	}
+
+	if c.state == BreakerOpen && b.now().Sub(c.openedAt) >= b.settings.OpenTimeout {
+		c.state = BreakerHalfOpen
+		c.trials = 0
+	}
+
+	return c
+}
+
+// allow returns ErrCircuitOpen if a request of the given class must not be sent
+func (b *Breaker) allow(class EndpointClass) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.circuit(class)
+	switch c.state {
+	case BreakerOpen:
+		return fmt.Errorf("%w for %s requests", ErrCircuitOpen, class)
+	case BreakerHalfOpen:
+		if c.trials >= b.settings.HalfOpenRequests {
+			return fmt.Errorf("%w for %s requests", ErrCircuitOpen, class)
+		}
+		c.trials++
+	}
+
+	return nil
+}
+
+// record records the outcome of a request allowed by allow
+func (b *Breaker) record(ctx context.Context, class EndpointClass, status int, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.circuit(class)
+
+	// A request cancelled by the caller says nothing about Zencoder's health
+	if err != nil && ctx.Err() != nil {
+		if c.state == BreakerHalfOpen && c.trials > 0 {
+			c.trials--
+		}
+		return
+	}
+
+	if err == nil && status < http.StatusInternalServerError {
+		c.state = BreakerClosed
+		c.failures = 0
+		return
+	}
+
+	c.failures++
+	if c.state == BreakerHalfOpen || c.failures >= b.settings.FailureThreshold {
+		c.state = BreakerOpen
+		c.openedAt = b.now()
+	}
+}
@@ -0,0 +1,160 @@
+package zencoder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestBreaker(t *testing.T) {
+	failing := true
+	calls := 0
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jobs/123/progress.json", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if failing {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, `{"state": "processing"}`)
+	})
+	mux.HandleFunc("/jobs/123/cancel.json", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	now := time.Now()
+	breaker := NewBreaker(BreakerSettings{FailureThreshold: 3, OpenTimeout: time.Minute})
+	breaker.now = func() time.Time { return now }
+
+	zc := NewZencoder("abc", WithBaseURL(srv.URL), WithBreaker(breaker))
+
+	for i := 0; i < 3; i++ {
+		if breaker.State(EndpointPoll) != BreakerClosed {
+			t.Fatal("Expected closed circuit", i)
+		}
+
+		if _, err := zc.GetJobProgress(123); err == nil {
+			t.Fatal("Expected error")
+		}
+	}
+
+	if breaker.State(EndpointPoll) != BreakerOpen {
+		t.Fatal("Expected open circuit", breaker.State(EndpointPoll))
+	}
+
+	// Open circuits fail fast without reaching Zencoder
+	_, err := zc.GetJobProgress(123)
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatal("Expected ErrCircuitOpen", err)
+	}
+
+	if calls != 3 {
+		t.Fatal("Expected no request while open", calls)
+	}
+
+	// Other endpoint classes are unaffected
+	if err := zc.CancelJob(123); err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if states := breaker.States(); states[EndpointMutate] != BreakerClosed || states[EndpointPoll] != BreakerOpen {
+		t.Fatal("Unexpected states", states)
+	}
+
+	// A failed trial opens the circuit again
+	now = now.Add(time.Minute)
+	if breaker.State(EndpointPoll) != BreakerHalfOpen {
+		t.Fatal("Expected half-open circuit", breaker.State(EndpointPoll))
+	}
+
+	if _, err := zc.GetJobProgress(123); errors.Is(err, ErrCircuitOpen) {
+		t.Fatal("Expected a trial request", err)
+	}
+
+	if breaker.State(EndpointPoll) != BreakerOpen {
+		t.Fatal("Expected open circuit", breaker.State(EndpointPoll))
+	}
+
+	// A successful trial closes it
+	now = now.Add(time.Minute)
+	failing = false
+
+	if _, err := zc.GetJobProgress(123); err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if breaker.State(EndpointPoll) != BreakerClosed {
+		t.Fatal("Expected closed circuit", breaker.State(EndpointPoll))
+	}
+}
+
+func TestBreakerHalfOpenTrials(t *testing.T) {
+	now := time.Now()
+	breaker := NewBreaker(BreakerSettings{FailureThreshold: 1, OpenTimeout: time.Second, HalfOpenRequests: 2})
+	breaker.now = func() time.Time { return now }
+
+	if err := breaker.allow(EndpointCreate); err != nil {
+		t.Fatal("Expected no error", err)
+	}
+	breaker.record(context.Background(), EndpointCreate, http.StatusBadGateway, nil)
+
+	if err := breaker.allow(EndpointCreate); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatal("Expected ErrCircuitOpen", err)
+	}
+
+	now = now.Add(time.Second)
+	for i := 0; i < 2; i++ {
+		if err := breaker.allow(EndpointCreate); err != nil {
+			t.Fatal("Expected a trial request", i, err)
+		}
+	}
+
+	if err := breaker.allow(EndpointCreate); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatal("Expected trial requests to be limited", err)
+	}
+
+	// 4xx responses are successes as far as the circuit is concerned
+	breaker.record(context.Background(), EndpointCreate, http.StatusUnprocessableEntity, nil)
+	if breaker.State(EndpointCreate) != BreakerClosed {
+		t.Fatal("Expected closed circuit", breaker.State(EndpointCreate))
+	}
+
+	if s := BreakerHalfOpen.String(); s != "half-open" {
+		t.Fatal("Unexpected String", s)
+	}
+}
+
+func TestBreakerNotRetried(t *testing.T) {
+	calls := 0
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jobs/123.json", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	zc := NewZencoder("abc",
+		WithBaseURL(srv.URL),
+		WithRetryPolicy(&RetryPolicy{MaxAttempts: 5, MinBackoff: time.Millisecond}),
+		WithBreaker(NewBreaker(BreakerSettings{FailureThreshold: 2})))
+
+	_, err := zc.GetJobDetails(123)
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatal("Expected ErrCircuitOpen once the circuit opens", err)
+	}
+
+	if calls != 2 {
+		t.Fatal("Expected retries to stop when the circuit opens", calls)
+	}
+}
@@ -0,0 +1,184 @@
+package zencoder
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// CacheSettings configures a Cache
+type CacheSettings struct {
+	MaxEntries int           // Entries kept before the least recently used is evicted, 1000 if zero
+	TTL        time.Duration // Time an entry is kept, 0 to keep it until evicted
+}
+
+// CacheStats reports how a Cache has been used
+type CacheStats struct {
+	Hits      int64 // Lookups answered from the cache
+	Misses    int64 // Lookups sent to Zencoder
+	Evictions int64 // Entries evicted to make room for newer ones
+	Entries   int   // Entries currently held
+}
+
+// Cache keeps the details of jobs and media files that reached a terminal
+// state, e.g. finished, failed or cancelled, which Zencoder no longer changes.
+//
+// GetJobDetails, GetInputDetails and GetOutputDetails consult the cache;
+// progress and listing calls are never cached.  Entries are kept per API
+// key, and ResubmitJob invalidates every entry of the resubmitted job.
+// Cached responses are decoded afresh on every hit, so callers may modify
+// what they get.  A Cache is safe for concurrent use.
+type Cache struct {
+	settings CacheSettings
+	now      func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List // Most recently used first
+	stats   CacheStats
+}
+
+type cacheEntry struct {
+	key     string
+	jobId   JobID
+	body    []byte
+	expires time.Time
+}
+
+// NewCache returns an empty Cache
+func NewCache(settings CacheSettings) *Cache {
+	if settings.MaxEntries <= 0 {
+		settings.MaxEntries = 1000
+	}
+
+	return &Cache{
+		settings: settings,
+		now:      time.Now,
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+// Stats returns usage statistics
+func (c *Cache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Entries = c.lru.Len()
+	return stats
+}
+
+// InvalidateJob removes the cached details of a job and of its media files
+func (c *Cache) InvalidateJob(id JobID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for e := c.lru.Front(); e != nil; {
+		next := e.Next()
+		if e.Value.(*cacheEntry).jobId == id {
+			c.remove(e)
+		}
+		e = next
+	}
+}
+
+// Purge removes every entry
+func (c *Cache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[string]*list.Element)
+	c.lru.Init()
+}
+
+// get decodes the entry for key into result, reporting whether there was one
+func (c *Cache) get(key string, result interface{}) bool {
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	if ok && c.expired(e.Value.(*cacheEntry)) {
+		c.remove(e)
+		ok = false
+	}
+
+	if !ok {
+		c.stats.Misses++
+		c.mu.Unlock()
+		return false
+	}
+
+	c.stats.Hits++
+	c.lru.MoveToFront(e)
+	body := e.Value.(*cacheEntry).body
+	c.mu.Unlock()
+
+	return json.Unmarshal(body, result) == nil
+}
+
+// put stores a copy of value under key, tagged with the job it belongs to
+func (c *Cache) put(key string, jobId JobID, value interface{}) {
+	body, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+
+	entry := &cacheEntry{key: key, jobId: jobId, body: body}
+	if c.settings.TTL > 0 {
+		entry.expires = c.now().Add(c.settings.TTL)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[key]; ok {
+		c.remove(e)
+	}
+
+	c.entries[key] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.settings.MaxEntries {
+		c.remove(c.lru.Back())
+		c.stats.Evictions++
+	}
+}
+
+// expired reports whether an entry has outlived the TTL.  c.mu must be held.
+func (c *Cache) expired(entry *cacheEntry) bool {
+	return !entry.expires.IsZero() && !c.now().Before(entry.expires)
+}
+
+// remove removes an entry.  c.mu must be held.
+func (c *Cache) remove(e *list.Element) {
+	c.lru.Remove(e)
+	delete(c.entries, e.Value.(*cacheEntry).key)
+}
+
+// cacheKey identifies a GET request by its path and the API key it is made with
+func (z *Zencoder) cacheKey(ctx context.Context, path string) string {
+	return z.requestHeader(ctx).Get("Zencoder-Api-Key") + " " + path
+}
+
+// getCached is getBody answered from the cache when possible.  cacheable is
+// called after a successful request and returns the job the response belongs
+// to, or false if it must not be cached.
+func (z *Zencoder) getCached(ctx context.Context, path string, response interface{}, cacheable func() (JobID, bool)) error {
+	if z.Cache == nil {
+		return z.getBody(ctx, path, response)
+	}
+
+	key := z.cacheKey(ctx, path)
+	if z.Cache.get(key, response) {
+		return nil
+	}
+
+	if err := z.getBody(ctx, path, response); err != nil {
+		return err
+	}
+
+	if jobId, ok := cacheable(); ok {
+		z.Cache.put(key, jobId, response)
+	}
+
+	return nil
+}
@@ -0,0 +1,146 @@
+package zencoder
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	requests := map[string]int{}
+	jobState := "processing"
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jobs/123.json", func(w http.ResponseWriter, r *http.Request) {
+		requests[r.Header.Get("Zencoder-Api-Key")+" "+r.URL.Path]++
+		fmt.Fprintf(w, `{"job": {"id": 123, "state": %q, "output_media_files": [{"id": 456, "state": "finished"}]}}`, jobState)
+	})
+	mux.HandleFunc("/outputs/456.json", func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		fmt.Fprintln(w, `{"id": 456, "job_id": 123, "state": "finished", "unknown": true}`)
+	})
+	mux.HandleFunc("/jobs/123/resubmit.json", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	cache := NewCache(CacheSettings{})
+	zc := NewZencoder("abc", WithBaseURL(srv.URL), WithCache(cache))
+
+	// Jobs still processing are not cached
+	for i := 0; i < 2; i++ {
+		if _, err := zc.GetJobDetails(123); err != nil {
+			t.Fatal("Expected no error", err)
+		}
+	}
+
+	if requests["abc /jobs/123.json"] != 2 {
+		t.Fatal("Expected processing jobs to be fetched every time", requests)
+	}
+
+	jobState = "finished"
+	for i := 0; i < 3; i++ {
+		details, err := zc.GetJobDetails(123)
+		if err != nil {
+			t.Fatal("Expected no error", err)
+		}
+
+		if details.Job.State != "finished" || details.Job.OutputMediaFiles[0].Id != 456 {
+			t.Fatal("Unexpected details", details.Job)
+		}
+
+		// Callers get their own copy
+		details.Job.State = "modified"
+	}
+
+	if requests["abc /jobs/123.json"] != 3 {
+		t.Fatal("Expected finished jobs to be cached", requests)
+	}
+
+	// Entries are kept per API key
+	if _, err := zc.GetJobDetailsContext(ContextWithAPIKey(context.Background(), "def"), 123); err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if requests["def /jobs/123.json"] != 1 {
+		t.Fatal("Expected a request with another API key", requests)
+	}
+
+	for i := 0; i < 2; i++ {
+		output, err := zc.GetOutputDetails(456)
+		if err != nil {
+			t.Fatal("Expected no error", err)
+		}
+
+		if output.JobId != 123 || output.Extra["unknown"] == nil {
+			t.Fatal("Expected the cached output in full", output.JobId, output.Extra)
+		}
+	}
+
+	if requests["/outputs/456.json"] != 1 {
+		t.Fatal("Expected finished outputs to be cached", requests)
+	}
+
+	stats := cache.Stats()
+	if stats.Hits != 3 || stats.Misses != 5 || stats.Entries != 3 {
+		t.Fatal("Unexpected stats", stats)
+	}
+
+	// Resubmitting invalidates the job and its media files
+	if err := zc.ResubmitJob(123); err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if stats := cache.Stats(); stats.Entries != 0 {
+		t.Fatal("Expected the job to be invalidated", stats)
+	}
+
+	if _, err := zc.GetOutputDetails(456); err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if requests["/outputs/456.json"] != 2 {
+		t.Fatal("Expected the output to be fetched again", requests)
+	}
+}
+
+func TestCacheEviction(t *testing.T) {
+	now := time.Now()
+	cache := NewCache(CacheSettings{MaxEntries: 2, TTL: time.Minute})
+	cache.now = func() time.Time { return now }
+
+	cache.put("a", 1, "a")
+	cache.put("b", 2, "b")
+
+	var value string
+	if !cache.get("a", &value) || value != "a" {
+		t.Fatal("Expected a hit", value)
+	}
+
+	// b is now the least recently used
+	cache.put("c", 3, "c")
+	if cache.get("b", &value) {
+		t.Fatal("Expected b to be evicted")
+	}
+
+	now = now.Add(time.Minute)
+	if cache.get("a", &value) || cache.get("c", &value) {
+		t.Fatal("Expected entries to expire")
+	}
+
+	stats := cache.Stats()
+	if stats.Evictions != 1 || stats.Hits != 1 || stats.Misses != 3 || stats.Entries != 0 {
+		t.Fatal("Unexpected stats", stats)
+	}
+
+	cache.put("a", 1, "a")
+	cache.Purge()
+	if cache.Stats().Entries != 0 {
+		t.Fatal("Expected no entries after Purge")
+	}
+}
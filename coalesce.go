@@ -0,0 +1,119 @@
+package zencoder
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// CoalescerStats reports how a Coalescer has been used
+type CoalescerStats struct {
+	Requests int64 // GET requests sent to Zencoder
+	Shared   int64 // Calls answered by a request already in flight
+}
+
+// Coalescer collapses identical in-flight GET requests, i.e. with the same
+// path and API key, into a single HTTP request whose response is shared by
+// every caller.
+//
+// The shared request is only cancelled once every caller waiting for it has
+// given up, so one caller's cancellation does not fail the others.  Hooks,
+// logging and metrics see the single request.  A Coalescer is safe for
+// concurrent use.
+type Coalescer struct {
+	mu      sync.Mutex
+	flights map[string]*flight
+	stats   CoalescerStats
+}
+
+type flight struct {
+	done    chan struct{}
+	body    []byte
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// NewCoalescer returns a Coalescer with no requests in flight
+func NewCoalescer() *Coalescer {
+	return &Coalescer{flights: make(map[string]*flight)}
+}
+
+// Stats returns usage statistics
+func (c *Coalescer) Stats() CoalescerStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.stats
+}
+
+// do returns the body fetched for key, joining the request in flight if any
+func (c *Coalescer) do(ctx context.Context, key string, fetch func(ctx context.Context) ([]byte, error)) ([]byte, error) {
+	c.mu.Lock()
+	f, ok := c.flights[key]
+	if ok {
+		c.stats.Shared++
+	} else {
+		c.stats.Requests++
+
+		// The request outlives the caller that started it, keeping its values
+		flightCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		f = &flight{done: make(chan struct{}), cancel: cancel}
+		c.flights[key] = f
+
+		go func() {
+			f.body, f.err = fetch(flightCtx)
+			cancel()
+
+			c.mu.Lock()
+			c.forget(key, f)
+			c.mu.Unlock()
+
+			close(f.done)
+		}()
+	}
+	f.waiters++
+	c.mu.Unlock()
+
+	select {
+	case <-f.done:
+		return f.body, f.err
+	case <-ctx.Done():
+		c.mu.Lock()
+		f.waiters--
+		if f.waiters == 0 {
+			f.cancel()
+			c.forget(key, f)
+		}
+		c.mu.Unlock()
+
+		return nil, ctx.Err()
+	}
+}
+
+// forget removes a flight so that later calls start a new one.  c.mu must be held.
+func (c *Coalescer) forget(key string, f *flight) {
+	if c.flights[key] == f {
+		delete(c.flights, key)
+	}
+}
+
+// getCoalesced is getBody sharing the request with identical calls in flight
+func (z *Zencoder) getCoalesced(ctx context.Context, path string, response interface{}) error {
+	body, err := z.Coalescer.do(ctx, z.cacheKey(ctx, path), func(ctx context.Context) ([]byte, error) {
+		resp, err := z.call(ctx, "GET", path, nil, []int{http.StatusOK})
+		if err != nil {
+			return nil, err
+		}
+
+		defer drainAndClose(resp.Body)
+		return io.ReadAll(newLimitReader(resp.Body, z.MaxResponseSize))
+	})
+	if err != nil {
+		return err
+	}
+
+	return z.decode(path, io.NopCloser(bytes.NewReader(body)), response)
+}
@@ -0,0 +1,147 @@
+package zencoder
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// waitForShared waits until n calls have joined a request in flight
+func waitForShared(t *testing.T, c *Coalescer, n int64) {
+	deadline := time.Now().Add(5 * time.Second)
+	for c.Stats().Shared < n {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for shared calls", c.Stats())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestCoalescer(t *testing.T) {
+	const callers = 20
+
+	var requests int32
+	release := make(chan struct{})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jobs/123/progress.json", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		<-release
+		fmt.Fprintln(w, `{"state": "processing", "progress": 42.5}`)
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	coalescer := NewCoalescer()
+	zc := NewZencoder("abc", WithBaseURL(srv.URL), WithCoalescer(coalescer))
+
+	var wg sync.WaitGroup
+	results := make([]*JobProgress, callers)
+	errs := make([]error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = zc.GetJobProgress(123)
+		}(i)
+	}
+
+	waitForShared(t, coalescer, callers-1)
+	close(release)
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Fatal("Expected a single request", n)
+	}
+
+	for i := 0; i < callers; i++ {
+		if errs[i] != nil {
+			t.Fatal("Expected no error", errs[i])
+		}
+
+		if results[i].JobProgress != 42.5 {
+			t.Fatal("Expected the shared progress", results[i])
+		}
+
+		if i > 0 && results[i] == results[0] {
+			t.Fatal("Expected every caller to get its own result")
+		}
+	}
+
+	// Finished requests are not shared with later calls
+	if _, err := zc.GetJobProgress(123); err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Fatal("Expected a new request", n)
+	}
+}
+
+func TestCoalescerCancellation(t *testing.T) {
+	var requests int32
+	release := make(chan struct{})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jobs/123.json", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		<-release
+		fmt.Fprintln(w, `{"job": {"id": 123}}`)
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	coalescer := NewCoalescer()
+	zc := NewZencoder("abc", WithBaseURL(srv.URL), WithCoalescer(coalescer))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error)
+	go func() {
+		_, err := zc.GetJobDetailsContext(ctx, 123)
+		first <- err
+	}()
+
+	second := make(chan error)
+	go func() {
+		_, err := zc.GetJobDetails(123)
+		second <- err
+	}()
+
+	// Requests with another API key are not shared
+	third := make(chan error)
+	go func() {
+		_, err := zc.GetJobDetailsContext(ContextWithAPIKey(context.Background(), "def"), 123)
+		third <- err
+	}()
+
+	waitForShared(t, coalescer, 1)
+	for atomic.LoadInt32(&requests) < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// The caller that started the request gives up; the other still gets the response
+	cancel()
+	if err := <-first; err != context.Canceled {
+		t.Fatal("Expected context.Canceled", err)
+	}
+
+	close(release)
+	if err := <-second; err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if err := <-third; err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if stats := coalescer.Stats(); stats.Requests != 2 || stats.Shared != 1 {
+		t.Fatal("Unexpected stats", stats)
+	}
+}
@@ -0,0 +1,168 @@
+package zencoder
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// DryRunCall is a mutating call that was recorded instead of being sent
+type DryRunCall struct {
+	Method string          // HTTP method
+	Path   string          // API path, relative to BaseUrl
+	Body   json.RawMessage // Exact JSON that would have been sent, nil for requests without a body
+}
+
+// DryRun records mutating calls instead of sending them to Zencoder.
+//
+// With a DryRun set on a client, every non-GET call (CreateJob, CancelJob,
+// ResubmitJob, SetLiveMode, ...) is recorded and answered with a synthesized
+// response: CreateJob returns fake job and output IDs with one output per
+// OutputSettings, CreateAccount returns a fake API key and the PUT endpoints
+// succeed.  GET requests are still sent.  Hooks and logging see dry-run calls
+// as usual.  A DryRun is safe for concurrent use.
+type DryRun struct {
+	mu     sync.Mutex
+	calls  []DryRunCall
+	nextId int64
+}
+
+// NewDryRun returns a DryRun with no recorded calls
+func NewDryRun() *DryRun {
+	return &DryRun{nextId: 1000}
+}
+
+// Calls returns the calls recorded so far, oldest first
+func (d *DryRun) Calls() []DryRunCall {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]DryRunCall(nil), d.calls...)
+}
+
+// Reset forgets the recorded calls
+func (d *DryRun) Reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.calls = nil
+}
+
+// intercepts reports whether a request is recorded rather than sent
+func (d *DryRun) intercepts(method string) bool {
+	return d != nil && method != "GET"
+}
+
+// respond records a request and synthesizes Zencoder's response to it
+func (d *DryRun) respond(info *CallInfo, body []byte) *http.Response {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	call := DryRunCall{Method: info.Method, Path: info.Path}
+	if body != nil {
+		call.Body = append(json.RawMessage(nil), body...)
+	}
+	d.calls = append(d.calls, call)
+
+	status := http.StatusNoContent
+	var result interface{}
+
+	if info.Method == "POST" {
+		status = http.StatusCreated
+		switch info.Path {
+		case "jobs":
+			result = d.createJobResponse(body)
+		case "account":
+			result = d.createAccountResponse(body)
+		default:
+			result = struct{}{}
+		}
+	}
+
+	resp := &http.Response{
+		Status:     fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode: status,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{},
+		Body:       http.NoBody,
+		Request:    info.Request,
+	}
+
+	if result != nil {
+		b, _ := json.Marshal(result)
+		resp.Header.Set("Content-Type", "application/json")
+		resp.ContentLength = int64(len(b))
+		resp.Body = io.NopCloser(bytes.NewReader(b))
+	}
+
+	return resp
+}
+
+// id returns a fake ID.  d.mu must be held.
+func (d *DryRun) id() int64 {
+	d.nextId++
+	return d.nextId
+}
+
+func (d *DryRun) createJobResponse(body []byte) *CreateJobResponse {
+	var settings EncodingSettings
+	json.Unmarshal(body, &settings)
+
+	result := &CreateJobResponse{
+		Id:   JobID(d.id()),
+		Test: settings.Test,
+	}
+
+	for _, output := range settings.Outputs {
+		if output == nil {
+			continue
+		}
+
+		created := CreateJobOutput{
+			Id:  OutputID(d.id()),
+			Url: output.Url,
+		}
+
+		if output.Label != "" {
+			label := output.Label
+			created.Label = &label
+		}
+
+		if created.Url == "" && output.BaseUrl != "" && output.Filename != "" {
+			created.Url = strings.TrimSuffix(output.BaseUrl, "/") + "/" + output.Filename
+		}
+
+		result.Outputs = append(result.Outputs, created)
+	}
+
+	return result
+}
+
+func (d *DryRun) createAccountResponse(body []byte) *CreateAccountResponse {
+	var request CreateAccountRequest
+	json.Unmarshal(body, &request)
+
+	result := &CreateAccountResponse{ApiKey: fakeSecret()}
+	if request.Password != nil {
+		result.Password = *request.Password
+	} else {
+		result.Password = fakeSecret()[:12]
+	}
+
+	return result
+}
+
+func fakeSecret() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,118 @@
+package zencoder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDryRun(t *testing.T) {
+	var sent []string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		sent = append(sent, r.Method+" "+r.URL.Path)
+		fmt.Fprintln(w, `{"state": "processing", "progress": 50}`)
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	var statuses []int
+	dryRun := NewDryRun()
+	zc := NewZencoder("abc", WithBaseURL(srv.URL), WithDryRun(dryRun), WithHooks(Hook{
+		AfterResponse: func(ctx context.Context, call *CallInfo) {
+			statuses = append(statuses, call.StatusCode)
+		},
+	}))
+
+	settings := &EncodingSettings{
+		Input: "s3://bucket/test.mov",
+		Test:  true,
+		Outputs: []*OutputSettings{
+			{Label: "hd", Url: "s3://bucket/hd.mp4"},
+			{BaseUrl: "s3://bucket/", Filename: "sd.mp4"},
+		},
+	}
+
+	resp, err := zc.CreateJob(settings)
+	if err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if resp.Id == 0 || !resp.Test {
+		t.Fatal("Expected a fake test job", resp)
+	}
+
+	if len(resp.Outputs) != 2 {
+		t.Fatal("Expected one output per OutputSettings", resp.Outputs)
+	}
+
+	if resp.Outputs[0].Id == 0 || resp.Outputs[0].Id == resp.Outputs[1].Id || int64(resp.Outputs[0].Id) == int64(resp.Id) {
+		t.Fatal("Expected distinct fake ids", resp)
+	}
+
+	if *resp.Outputs[0].Label != "hd" || resp.Outputs[0].Url != "s3://bucket/hd.mp4" {
+		t.Fatal("Unexpected first output", resp.Outputs[0])
+	}
+
+	if resp.Outputs[1].Label != nil || resp.Outputs[1].Url != "s3://bucket/sd.mp4" {
+		t.Fatal("Unexpected second output", resp.Outputs[1])
+	}
+
+	if err := zc.CancelJob(resp.Id); err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if err := zc.SetLiveMode(); err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	account, err := zc.CreateAccount("test@example.com", "secret")
+	if err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if len(account.ApiKey) != 32 || account.Password != "secret" {
+		t.Fatal("Unexpected account", account)
+	}
+
+	// Reads are still sent
+	if _, err := zc.GetJobProgress(resp.Id); err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if len(sent) != 1 || sent[0] != fmt.Sprintf("GET /jobs/%d/progress.json", resp.Id) {
+		t.Fatal("Expected only reads to reach Zencoder", sent)
+	}
+
+	calls := dryRun.Calls()
+	if len(calls) != 4 {
+		t.Fatal("Expected 4 recorded calls", calls)
+	}
+
+	expected, _ := json.Marshal(settings)
+	if calls[0].Method != "POST" || calls[0].Path != "jobs" || string(calls[0].Body) != string(expected) {
+		t.Fatal("Expected the exact CreateJob body", calls[0].Method, calls[0].Path, string(calls[0].Body))
+	}
+
+	if calls[1].Method != "PUT" || calls[1].Path != fmt.Sprintf("jobs/%d/cancel.json", resp.Id) || calls[1].Body != nil {
+		t.Fatal("Unexpected CancelJob call", calls[1])
+	}
+
+	if calls[2].Path != "account/live" || calls[3].Path != "account" {
+		t.Fatal("Unexpected account calls", calls[2], calls[3])
+	}
+
+	if len(statuses) != 5 || statuses[0] != http.StatusCreated || statuses[1] != http.StatusNoContent {
+		t.Fatal("Expected hooks to see dry-run calls", statuses)
+	}
+
+	dryRun.Reset()
+	if len(dryRun.Calls()) != 0 {
+		t.Fatal("Expected no calls after Reset")
+	}
+}
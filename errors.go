@@ -0,0 +1,73 @@
+package zencoder
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Sentinel errors matched by an APIError through errors.Is
+var (
+	ErrNotFound        = errors.New("zencoder: not found")
+	ErrUnauthorized    = errors.New("zencoder: unauthorized")
+	ErrPaymentRequired = errors.New("zencoder: payment required")
+	ErrRateLimited     = errors.New("zencoder: rate limited")
+	ErrValidation      = errors.New("zencoder: validation failed")
+)
+
+// APIError is returned when the Zencoder API responds with an unexpected status
+type APIError struct {
+	Method     string   // HTTP method of the request
+	Path       string   // API path of the request, relative to BaseUrl
+	StatusCode int      // HTTP status code of the response
+	Status     string   // HTTP status line of the response, e.g. "404 Not Found"
+	Body       []byte   // Raw response body
+	Errors     []string // Messages from the "errors" list of the response body, if any
+}
+
+func (e *APIError) Error() string {
+	if len(e.Errors) > 0 {
+		return fmt.Sprintf("zencoder: %s %s: %s: %q", e.Method, e.Path, e.Status, e.Errors)
+	}
+
+	return fmt.Sprintf("zencoder: %s %s: %s. Body: %s", e.Method, e.Path, e.Status, string(e.Body))
+}
+
+// Is reports whether the error matches one of the sentinel errors
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrPaymentRequired:
+		return e.StatusCode == http.StatusPaymentRequired
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrValidation:
+		return e.StatusCode == http.StatusUnprocessableEntity
+	}
+
+	return false
+}
+
+func newAPIError(method, path string, resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{
+		Method:     method,
+		Path:       path,
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Body:       body,
+	}
+
+	// Zencoder reports failures as {"errors": ["..."]}; anything else is left in Body
+	var parsed struct {
+		Errors []string `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &parsed); err == nil {
+		apiErr.Errors = parsed.Errors
+	}
+
+	return apiErr
+}
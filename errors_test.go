@@ -0,0 +1,104 @@
+package zencoder
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAPIError(t *testing.T) {
+	expectedStatus := http.StatusNotFound
+	body := `{"errors": ["Job not found"]}`
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jobs/123.json", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(expectedStatus)
+		fmt.Fprint(w, body)
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	zc := NewZencoder("abc")
+	zc.BaseUrl = srv.URL
+
+	_, err := zc.GetJobDetails(123)
+	if err == nil {
+		t.Fatal("Expected error")
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatal("Expected an APIError", err)
+	}
+
+	if apiErr.StatusCode != http.StatusNotFound {
+		t.Fatal("Expected StatusCode=404", apiErr.StatusCode)
+	}
+
+	if apiErr.Method != "GET" {
+		t.Fatal("Expected Method=GET", apiErr.Method)
+	}
+
+	if apiErr.Path != "jobs/123.json" {
+		t.Fatal("Expected Path=jobs/123.json", apiErr.Path)
+	}
+
+	if string(apiErr.Body) != body {
+		t.Fatal("Expected raw body", string(apiErr.Body))
+	}
+
+	if len(apiErr.Errors) != 1 || apiErr.Errors[0] != "Job not found" {
+		t.Fatal("Expected parsed errors", apiErr.Errors)
+	}
+
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatal("Expected ErrNotFound", err)
+	}
+
+	if errors.Is(err, ErrUnauthorized) {
+		t.Fatal("Expected not ErrUnauthorized", err)
+	}
+
+	expectedStatus = http.StatusInternalServerError
+	body = "oops"
+
+	_, err = zc.GetJobDetails(123)
+	if !errors.As(err, &apiErr) {
+		t.Fatal("Expected an APIError", err)
+	}
+
+	if len(apiErr.Errors) != 0 {
+		t.Fatal("Expected no parsed errors", apiErr.Errors)
+	}
+
+	if string(apiErr.Body) != "oops" {
+		t.Fatal("Expected raw body", string(apiErr.Body))
+	}
+}
+
+func TestAPIErrorIs(t *testing.T) {
+	tests := []struct {
+		status   int
+		sentinel error
+	}{
+		{http.StatusNotFound, ErrNotFound},
+		{http.StatusUnauthorized, ErrUnauthorized},
+		{http.StatusPaymentRequired, ErrPaymentRequired},
+		{http.StatusTooManyRequests, ErrRateLimited},
+		{http.StatusUnprocessableEntity, ErrValidation},
+	}
+
+	sentinels := []error{ErrNotFound, ErrUnauthorized, ErrPaymentRequired, ErrRateLimited, ErrValidation}
+
+	for _, test := range tests {
+		err := error(&APIError{StatusCode: test.status})
+		for _, sentinel := range sentinels {
+			if errors.Is(err, sentinel) != (sentinel == test.sentinel) {
+				t.Fatal("Unexpected errors.Is result", test.status, sentinel)
+			}
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package zencoder
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// structFields maps the lower-cased JSON names of a struct's fields, including
+// promoted ones, to their types
+var structFieldsCache sync.Map // reflect.Type -> map[string]reflect.Type
+
+func structFields(t reflect.Type) map[string]reflect.Type {
+	if fields, ok := structFieldsCache.Load(t); ok {
+		return fields.(map[string]reflect.Type)
+	}
+
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name := strings.Split(tag, ",")[0]
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for k, v := range structFields(embedded) {
+					if _, ok := fields[k]; !ok {
+						fields[k] = v
+					}
+				}
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		fields[strings.ToLower(name)] = field.Type
+	}
+
+	structFieldsCache.Store(t, fields)
+	return fields
+}
+
+// decodeExtra decodes b into v, a pointer to a struct without a custom
+// UnmarshalJSON, and returns the fields of b that v does not know about
+func decodeExtra(b []byte, v interface{}) (map[string]json.RawMessage, error) {
+	if err := json.Unmarshal(b, v); err != nil {
+		return nil, err
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, err
+	}
+
+	known := structFields(reflect.TypeOf(v).Elem())
+
+	var extra map[string]json.RawMessage
+	for key, value := range raw {
+		if _, ok := known[strings.ToLower(key)]; ok {
+			continue
+		}
+
+		if extra == nil {
+			extra = make(map[string]json.RawMessage)
+		}
+		extra[key] = value
+	}
+
+	return extra, nil
+}
+
+// encodeExtra encodes v, a struct without a custom MarshalJSON, adding the
+// fields of extra that v does not already set
+func encodeExtra(v interface{}, extra map[string]json.RawMessage) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil || len(extra) == 0 {
+		return b, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+
+	for key, value := range extra {
+		if _, ok := fields[key]; !ok {
+			fields[key] = value
+		}
+	}
+
+	return json.Marshal(fields)
+}
+
+// unknownFields returns the dotted paths of the fields in raw, a generic JSON
+// value, that have no counterpart in type t
+func unknownFields(raw interface{}, t reflect.Type) []string {
+	seen := make(map[string]bool)
+	collectUnknownFields(raw, t, "", seen)
+
+	paths := make([]string, 0, len(seen))
+	for path := range seen {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	return paths
+}
+
+func collectUnknownFields(raw interface{}, t reflect.Type, prefix string, seen map[string]bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch value := raw.(type) {
+	case map[string]interface{}:
+		switch t.Kind() {
+		case reflect.Struct:
+			fields := structFields(t)
+			for key, child := range value {
+				fieldType, ok := fields[strings.ToLower(key)]
+				if !ok {
+					seen[prefix+key] = true
+					continue
+				}
+				collectUnknownFields(child, fieldType, prefix+key+".", seen)
+			}
+		case reflect.Map:
+			for key, child := range value {
+				collectUnknownFields(child, t.Elem(), prefix+key+".", seen)
+			}
+		}
+	case []interface{}:
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			for _, child := range value {
+				collectUnknownFields(child, t.Elem(), prefix, seen)
+			}
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package zencoder
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestJobExtra(t *testing.T) {
+	body := `{
+  "id": 1,
+  "state": "finished",
+  "priority": 5,
+  "input_media_file": {"id": 2, "url": "s3://bucket/in.mov", "hdr": {"format": "hdr10"}},
+  "output_media_files": [{"id": 3, "label": "hd", "rung": 4}]
+}`
+
+	var job Job
+	if err := json.Unmarshal([]byte(body), &job); err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if job.Id != 1 || job.State != "finished" {
+		t.Fatal("Expected known fields to be decoded", job.Id, job.State)
+	}
+
+	if string(job.Extra["priority"]) != "5" || len(job.Extra) != 1 {
+		t.Fatal("Expected priority in Extra", job.Extra)
+	}
+
+	if string(job.InputMediaFile.Extra["hdr"]) != `{"format": "hdr10"}` {
+		t.Fatal("Expected hdr in input Extra", job.InputMediaFile.Extra)
+	}
+
+	if string(job.OutputMediaFiles[0].Extra["rung"]) != "4" {
+		t.Fatal("Expected rung in output Extra", job.OutputMediaFiles[0].Extra)
+	}
+
+	b, err := json.Marshal(&job)
+	if err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	var again Job
+	if err := json.Unmarshal(b, &again); err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if !reflect.DeepEqual(compact(t, job.Extra), compact(t, again.Extra)) {
+		t.Fatal("Expected Extra to survive re-marshalling", string(b))
+	}
+
+	if string(again.OutputMediaFiles[0].Extra["rung"]) != "4" || again.InputMediaFile.Url != "s3://bucket/in.mov" {
+		t.Fatal("Expected nested fields to survive re-marshalling", string(b))
+	}
+
+	// Known fields win over stale Extra entries
+	job.Extra["state"] = json.RawMessage(`"bogus"`)
+	b, _ = json.Marshal(job)
+	json.Unmarshal(b, &again)
+	if again.State != "finished" {
+		t.Fatal("Expected known field to take precedence", again.State)
+	}
+
+	// Without unknown fields, Extra stays nil
+	var plain Job
+	json.Unmarshal([]byte(`{"id": 1}`), &plain)
+	if plain.Extra != nil {
+		t.Fatal("Expected no Extra", plain.Extra)
+	}
+}
+
+func TestMediaFileJobIdExtra(t *testing.T) {
+	body := `{"id": 2, "job_id": 1, "state": "finished", "new_field": true}`
+
+	var output OutputMediaFile
+	if err := json.Unmarshal([]byte(body), &output); err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if output.JobId != 1 || output.Id != 2 {
+		t.Fatal("Expected JobId and Id", output.JobId, output.Id)
+	}
+
+	if len(output.Extra) != 1 || string(output.Extra["new_field"]) != "true" {
+		t.Fatal("Expected only new_field in Extra", output.Extra)
+	}
+
+	b, err := json.Marshal(&output)
+	if err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	var again InputMediaFile
+	if err := json.Unmarshal(b, &again); err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if again.JobId != 1 || again.Id != 2 || string(again.Extra["new_field"]) != "true" {
+		t.Fatal("Expected fields to survive re-marshalling", string(b))
+	}
+}
+
+func TestNotificationExtra(t *testing.T) {
+	body := `{
+  "job": {"id": 1, "state": "finished", "sla": "gold"},
+  "outputs": [{"id": 3, "job_id": 1, "state": "finished"}],
+  "input": {"id": 2, "job_id": 1},
+  "delivery_attempt": 2
+}`
+
+	var n Notification
+	if err := json.Unmarshal([]byte(body), &n); err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if string(n.Extra["delivery_attempt"]) != "2" {
+		t.Fatal("Expected delivery_attempt in Extra", n.Extra)
+	}
+
+	if string(n.Job.Extra["sla"]) != `"gold"` {
+		t.Fatal("Expected sla in job Extra", n.Job.Extra)
+	}
+
+	if n.Outputs[0].JobId != 1 || n.Input.JobId != 1 {
+		t.Fatal("Expected JobIds", n.Outputs[0].JobId, n.Input.JobId)
+	}
+
+	b, _ := json.Marshal(n)
+	var again Notification
+	json.Unmarshal(b, &again)
+	if string(again.Extra["delivery_attempt"]) != "2" || string(again.Job.Extra["sla"]) != `"gold"` {
+		t.Fatal("Expected Extra to survive re-marshalling", string(b))
+	}
+}
+
+func TestStrictDecoding(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jobs/123.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"job": {"id": 123, "priority": 1, "output_media_files": [{"id": 1, "rung": 1}, {"id": 2, "rung": 2}]}, "meta": {}}`)
+	})
+	mux.HandleFunc("/jobs/123/progress.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"state": "processing", "outputs": [{"id": 1, "eta": 10}]}`)
+	})
+	mux.HandleFunc("/account", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"plan": "Growth"}`)
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	reported := make(map[string][]string)
+	zc := NewZencoder("abc", WithBaseURL(srv.URL), WithStrictDecoding(func(path string, fields []string) {
+		reported[path] = fields
+	}))
+
+	details, err := zc.GetJobDetails(123)
+	if err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if details.Job.Id != 123 {
+		t.Fatal("Expected Id=123", details.Job.Id)
+	}
+
+	expected := []string{"job.output_media_files.rung", "job.priority", "meta"}
+	if !reflect.DeepEqual(reported["jobs/123.json"], expected) {
+		t.Fatal("Expected drift to be reported", reported)
+	}
+
+	if _, err := zc.GetJobProgress(123); err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if !reflect.DeepEqual(reported["jobs/123/progress.json"], []string{"outputs.eta"}) {
+		t.Fatal("Expected drift to be reported", reported)
+	}
+
+	if _, err := zc.GetAccount(); err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if _, ok := reported["account"]; ok {
+		t.Fatal("Expected no drift for known fields", reported["account"])
+	}
+}
+
+func compact(t *testing.T, extra map[string]json.RawMessage) map[string]string {
+	out := make(map[string]string, len(extra))
+	for key, value := range extra {
+		var v interface{}
+		if err := json.Unmarshal(value, &v); err != nil {
+			t.Fatal(err)
+		}
+		b, _ := json.Marshal(v)
+		out[key] = string(b)
+	}
+	return out
+}
@@ -0,0 +1,216 @@
+package zencoder
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EndpointClass groups API endpoints that share a request budget
+type EndpointClass string
+
+const (
+	EndpointCreate EndpointClass = "create" // Job and account creation
+	EndpointPoll   EndpointClass = "poll"   // Job, input, output and account details and progress
+	EndpointMutate EndpointClass = "mutate" // Cancel, resubmit, finish and account mode changes
+	EndpointReport EndpointClass = "report" // Usage reports
+)
+
+// classify returns the EndpointClass of a request
+func classify(method, path string) EndpointClass {
+	switch {
+	case method == "POST":
+		return EndpointCreate
+	case method != "GET":
+		return EndpointMutate
+	case strings.HasPrefix(path, "reports/"):
+		return EndpointReport
+	}
+
+	return EndpointPoll
+}
+
+// Limit is a request budget
+type Limit struct {
+	Rate        float64 // Sustained requests per second, 0 for unlimited
+	Burst       int     // Requests allowed in a burst above Rate, at least 1
+	MaxInFlight int     // Maximum concurrent requests, 0 for unlimited
+}
+
+// GovernorStats reports how a budget has been used
+type GovernorStats struct {
+	Requests int64         // Requests admitted
+	Waiting  time.Duration // Total time requests spent waiting to be admitted
+}
+
+// Governor limits the rate and concurrency of requests made by a Zencoder client.
+//
+// Each EndpointClass with its own Limit (see SetLimit) has a separate budget;
+// all other classes share the default budget.  A Governor is safe for
+// concurrent use and may be shared by several clients.
+type Governor struct {
+	mu       sync.Mutex
+	fallback *budget
+	budgets  map[EndpointClass]*budget
+	stats    map[EndpointClass]*GovernorStats
+}
+
+// NewGovernor returns a Governor applying limit to every endpoint class
+func NewGovernor(limit Limit) *Governor {
+	return &Governor{
+		fallback: newBudget(limit),
+		budgets:  make(map[EndpointClass]*budget),
+		stats:    make(map[EndpointClass]*GovernorStats),
+	}
+}
+
+// SetLimit gives an endpoint class its own budget
+func (g *Governor) SetLimit(class EndpointClass, limit Limit) *Governor {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.budgets[class] = newBudget(limit)
+	return g
+}
+
+// Stats returns usage statistics per endpoint class
+func (g *Governor) Stats() map[EndpointClass]GovernorStats {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	stats := make(map[EndpointClass]GovernorStats, len(g.stats))
+	for class, s := range g.stats {
+		stats[class] = *s
+	}
+
+	return stats
+}
+
+// acquire waits until a request of the given class may proceed.  The returned
+// function must be called once the request has completed.
+func (g *Governor) acquire(ctx context.Context, class EndpointClass) (func(), error) {
+	g.mu.Lock()
+	b, ok := g.budgets[class]
+	if !ok {
+		b = g.fallback
+	}
+	g.mu.Unlock()
+
+	start := time.Now()
+	release, err := b.acquire(ctx)
+	waiting := time.Since(start)
+
+	g.mu.Lock()
+	s, ok := g.stats[class]
+	if !ok {
+		s = &GovernorStats{}
+		g.stats[class] = s
+	}
+	s.Waiting += waiting
+	if err == nil {
+		s.Requests++
+	}
+	g.mu.Unlock()
+
+	return release, err
+}
+
+// budget is a token bucket combined with a concurrency semaphore
+type budget struct {
+	limit Limit
+	slots chan struct{}
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newBudget(limit Limit) *budget {
+	if limit.Burst < 1 {
+		limit.Burst = 1
+	}
+
+	b := &budget{
+		limit:  limit,
+		tokens: float64(limit.Burst),
+		last:   time.Now(),
+	}
+
+	if limit.MaxInFlight > 0 {
+		b.slots = make(chan struct{}, limit.MaxInFlight)
+	}
+
+	return b
+}
+
+func (b *budget) acquire(ctx context.Context) (func(), error) {
+	if err := b.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	if b.slots == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case b.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { <-b.slots })
+	}, nil
+}
+
+// wait takes a token from the bucket, sleeping until one is available
+func (b *budget) wait(ctx context.Context) error {
+	if b.limit.Rate <= 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.limit.Rate
+	if max := float64(b.limit.Burst); b.tokens > max {
+		b.tokens = max
+	}
+	b.last = now
+
+	// Reserve a token; a negative balance is the queue of waiting requests
+	b.tokens--
+	var delay time.Duration
+	if b.tokens < 0 {
+		delay = time.Duration(-b.tokens / b.limit.Rate * float64(time.Second))
+	}
+	b.mu.Unlock()
+
+	if delay == 0 {
+		return nil
+	}
+
+	if err := sleep(ctx, delay); err != nil {
+		// Hand the reservation back so later requests are not delayed for it
+		b.mu.Lock()
+		b.tokens++
+		b.mu.Unlock()
+		return err
+	}
+
+	return nil
+}
+
+// releaseOnClose releases a governor slot when the response body is closed
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+}
+
+func (r *releaseOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.release()
+	return err
+}
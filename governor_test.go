@@ -0,0 +1,159 @@
+package zencoder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		class  EndpointClass
+	}{
+		{"POST", "jobs", EndpointCreate},
+		{"POST", "account", EndpointCreate},
+		{"GET", "jobs/123/progress.json", EndpointPoll},
+		{"GET", "outputs/123.json", EndpointPoll},
+		{"GET", "account", EndpointPoll},
+		{"PUT", "jobs/123/cancel.json", EndpointMutate},
+		{"PUT", "account/live", EndpointMutate},
+		{"GET", "reports/vod?from=2013-01-01", EndpointReport},
+	}
+
+	for _, test := range tests {
+		if class := classify(test.method, test.path); class != test.class {
+			t.Fatal("Unexpected class", test.method, test.path, class)
+		}
+	}
+}
+
+func TestGovernorMaxInFlight(t *testing.T) {
+	var inFlight, maxInFlight int32
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/outputs/123/progress.json", func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		fmt.Fprintln(w, `{"state": "processing"}`)
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	zc := NewZencoder("abc")
+	zc.BaseUrl = srv.URL
+	zc.Governor = NewGovernor(Limit{MaxInFlight: 2})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := zc.GetOutputProgress(123); err != nil {
+				t.Error("Expected no error", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if atomic.LoadInt32(&maxInFlight) > 2 {
+		t.Fatal("Expected at most 2 requests in flight", maxInFlight)
+	}
+
+	stats := zc.Governor.Stats()[EndpointPoll]
+	if stats.Requests != 10 {
+		t.Fatal("Expected 10 requests", stats.Requests)
+	}
+
+	if stats.Waiting == 0 {
+		t.Fatal("Expected time spent waiting")
+	}
+}
+
+func TestGovernorRate(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jobs", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintln(w, `{"id": 1234}`)
+	})
+	mux.HandleFunc("/jobs/123/progress.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"state": "processing"}`)
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	zc := NewZencoder("abc")
+	zc.BaseUrl = srv.URL
+	zc.Governor = NewGovernor(Limit{}).SetLimit(EndpointCreate, Limit{Rate: 20, Burst: 1})
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if _, err := zc.CreateJob(&EncodingSettings{}); err != nil {
+			t.Fatal("Expected no error", err)
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed < 180*time.Millisecond {
+		t.Fatal("Expected job creation to be rate limited", elapsed)
+	}
+
+	// Polling has its own, unlimited budget
+	start = time.Now()
+	for i := 0; i < 5; i++ {
+		if _, err := zc.GetJobProgress(123); err != nil {
+			t.Fatal("Expected no error", err)
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Fatal("Expected polling not to be rate limited", elapsed)
+	}
+
+	stats := zc.Governor.Stats()
+	if stats[EndpointCreate].Waiting < 150*time.Millisecond {
+		t.Fatal("Expected job creation to wait", stats[EndpointCreate].Waiting)
+	}
+
+	if stats[EndpointPoll].Requests != 5 {
+		t.Fatal("Expected 5 polling requests", stats[EndpointPoll].Requests)
+	}
+}
+
+func TestGovernorContext(t *testing.T) {
+	g := NewGovernor(Limit{Rate: 1, Burst: 1})
+
+	release, err := g.acquire(context.Background(), EndpointPoll)
+	if err != nil {
+		t.Fatal("Expected no error", err)
+	}
+	release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = g.acquire(ctx, EndpointPoll)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal("Expected context.DeadlineExceeded", err)
+	}
+
+	if stats := g.Stats()[EndpointPoll]; stats.Requests != 1 {
+		t.Fatal("Expected 1 admitted request", stats.Requests)
+	}
+}
@@ -0,0 +1,44 @@
+package zencoder
+
+import (
+	"context"
+	"net/http"
+)
+
+type headerOverridesKey struct{}
+
+// ContextWithHeader returns a context that sets an HTTP header on every
+// request made with it, replacing any value from Zencoder.Header.
+func ContextWithHeader(ctx context.Context, key, value string) context.Context {
+	parent, _ := ctx.Value(headerOverridesKey{}).(http.Header)
+
+	overrides := parent.Clone()
+	if overrides == nil {
+		overrides = make(http.Header)
+	}
+	overrides.Set(key, value)
+
+	return context.WithValue(ctx, headerOverridesKey{}, overrides)
+}
+
+// ContextWithAPIKey returns a context whose requests authenticate with apiKey
+// instead of the client's key, e.g. to act on behalf of a sub-account.
+func ContextWithAPIKey(ctx context.Context, apiKey string) context.Context {
+	return ContextWithHeader(ctx, "Zencoder-Api-Key", apiKey)
+}
+
+// requestHeader returns a copy of the client headers with the overrides from ctx applied
+func (z *Zencoder) requestHeader(ctx context.Context) http.Header {
+	header := z.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+
+	if overrides, ok := ctx.Value(headerOverridesKey{}).(http.Header); ok {
+		for key, values := range overrides {
+			header[key] = values
+		}
+	}
+
+	return header
+}
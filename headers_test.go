@@ -0,0 +1,81 @@
+package zencoder
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestHeaderOverrides(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/account", func(w http.ResponseWriter, r *http.Request) {
+		// Echo the key and trace id back as the plan so callers can check them
+		fmt.Fprintf(w, `{"plan": "%s/%s"}`, r.Header.Get("Zencoder-Api-Key"), r.Header.Get("X-Trace-Id"))
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	zc := NewZencoder("abc")
+	zc.BaseUrl = srv.URL
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			ctx := context.Background()
+			expected := "abc/"
+			if i%2 == 1 {
+				key := fmt.Sprintf("sub-%d", i)
+				trace := fmt.Sprintf("trace-%d", i)
+				ctx = ContextWithHeader(ContextWithAPIKey(ctx, key), "X-Trace-Id", trace)
+				expected = key + "/" + trace
+			}
+
+			account, err := zc.GetAccountContext(ctx)
+			if err != nil {
+				t.Error("Expected no error", err)
+				return
+			}
+
+			if account.Plan != expected {
+				t.Error("Expected", expected, "got", account.Plan)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if len(zc.Header["X-Trace-Id"]) != 0 {
+		t.Fatal("Expected client headers to be left untouched", zc.Header)
+	}
+
+	if zc.Header.Get("Zencoder-Api-Key") != "abc" {
+		t.Fatal("Expected client API key to be left untouched", zc.Header)
+	}
+}
+
+func TestContextWithHeader(t *testing.T) {
+	parent := ContextWithHeader(context.Background(), "X-One", "1")
+	child := ContextWithHeader(parent, "X-Two", "2")
+
+	zc := NewZencoder("abc")
+
+	header := zc.requestHeader(parent)
+	if header.Get("X-One") != "1" || header.Get("X-Two") != "" {
+		t.Fatal("Expected parent overrides only", header)
+	}
+
+	header = zc.requestHeader(child)
+	if header.Get("X-One") != "1" || header.Get("X-Two") != "2" {
+		t.Fatal("Expected parent and child overrides", header)
+	}
+
+	if header.Get("Zencoder-Api-Key") != "abc" {
+		t.Fatal("Expected client headers", header)
+	}
+}
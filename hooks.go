@@ -0,0 +1,78 @@
+package zencoder
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// CallInfo describes one attempt of an API call, as seen by hooks
+type CallInfo struct {
+	Method     string        // HTTP method
+	Path       string        // API path, relative to BaseUrl
+	Class      EndpointClass // Endpoint class of the path
+	Payload    interface{}   // Request payload before encoding, nil for requests without a body
+	Request    *http.Request // Outgoing request; BeforeRequest hooks may change its headers
+	Attempt    int           // Attempt number, starting at 1 and incremented by retries
+	StatusCode int           // Response status code, once a response has been received
+	Latency    time.Duration // Time spent waiting for the response
+}
+
+// Hook is a set of callbacks run around every request.  Any callback may be nil.
+//
+// Hooks run in the order they were registered, once per attempt, so a
+// retried call runs BeforeRequest again and may e.g. rotate credentials.
+type Hook struct {
+	// BeforeRequest runs before the request is sent.  Returning an error aborts
+	// the call with a *HookError: the request is neither sent nor retried.
+	BeforeRequest func(ctx context.Context, call *CallInfo) error
+
+	// AfterResponse runs when a response has been received, whatever its status.
+	AfterResponse func(ctx context.Context, call *CallInfo)
+
+	// OnError runs when an attempt fails without a response, or a call fails
+	// with an unexpected status (err is then an *APIError).
+	OnError func(ctx context.Context, call *CallInfo, err error)
+}
+
+// HookError is returned when a BeforeRequest hook aborts a call
+type HookError struct {
+	Err error // Error returned by the hook
+}
+
+func (e *HookError) Error() string {
+	return fmt.Sprintf("zencoder: aborted by hook: %v", e.Err)
+}
+
+func (e *HookError) Unwrap() error {
+	return e.Err
+}
+
+// Use registers hooks on the client.  It must not be called concurrently with requests.
+func (z *Zencoder) Use(hooks ...Hook) {
+	z.Hooks = append(z.Hooks, hooks...)
+}
+
+// WithHooks registers hooks on the client
+func WithHooks(hooks ...Hook) Option {
+	return func(z *Zencoder) {
+		z.Use(hooks...)
+	}
+}
+
+func (z *Zencoder) afterResponse(ctx context.Context, info *CallInfo) {
+	for _, hook := range z.Hooks {
+		if hook.AfterResponse != nil {
+			hook.AfterResponse(ctx, info)
+		}
+	}
+}
+
+func (z *Zencoder) onError(ctx context.Context, info *CallInfo, err error) {
+	for _, hook := range z.Hooks {
+		if hook.OnError != nil {
+			hook.OnError(ctx, info, err)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package zencoder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHooks(t *testing.T) {
+	var requestId string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jobs", func(w http.ResponseWriter, r *http.Request) {
+		requestId = r.Header.Get("X-Request-Id")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintln(w, `{"id": 1234}`)
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	var order []string
+	var after *CallInfo
+	var failed error
+
+	zc := NewZencoder("abc", WithBaseURL(srv.URL), WithHooks(Hook{
+		BeforeRequest: func(ctx context.Context, call *CallInfo) error {
+			order = append(order, "first")
+			call.Request.Header.Set("X-Request-Id", "req-1")
+			return nil
+		},
+		AfterResponse: func(ctx context.Context, call *CallInfo) {
+			after = call
+		},
+		OnError: func(ctx context.Context, call *CallInfo, err error) {
+			failed = err
+		},
+	}))
+	zc.Use(Hook{
+		BeforeRequest: func(ctx context.Context, call *CallInfo) error {
+			order = append(order, "second")
+			return nil
+		},
+	})
+
+	settings := &EncodingSettings{Input: "s3://bucket/test.mov"}
+	if _, err := zc.CreateJob(settings); err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if requestId != "req-1" {
+		t.Fatal("Expected BeforeRequest to set a header", requestId)
+	}
+
+	if len(order) != 2 || order[0] != "first" || order[1] != "second" {
+		t.Fatal("Expected hooks to run in order", order)
+	}
+
+	if after == nil {
+		t.Fatal("Expected AfterResponse to run")
+	}
+
+	if after.Method != "POST" || after.Path != "jobs" || after.Class != EndpointCreate {
+		t.Fatal("Unexpected call", after.Method, after.Path, after.Class)
+	}
+
+	if after.Payload != settings {
+		t.Fatal("Expected the request payload", after.Payload)
+	}
+
+	if after.StatusCode != http.StatusCreated {
+		t.Fatal("Expected StatusCode=201", after.StatusCode)
+	}
+
+	if after.Latency <= 0 {
+		t.Fatal("Expected latency", after.Latency)
+	}
+
+	if failed != nil {
+		t.Fatal("Expected OnError not to run", failed)
+	}
+
+	// Unexpected statuses run OnError with the APIError
+	_, err := zc.GetJobDetails(123)
+	if err == nil {
+		t.Fatal("Expected error")
+	}
+
+	if !errors.Is(failed, ErrNotFound) {
+		t.Fatal("Expected OnError to see ErrNotFound", failed)
+	}
+
+	if after.StatusCode != http.StatusNotFound {
+		t.Fatal("Expected AfterResponse to see StatusCode=404", after.StatusCode)
+	}
+}
+
+func TestHooksAbort(t *testing.T) {
+	requests := 0
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jobs/123/cancel.json", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	abort := errors.New("abort")
+	var failed error
+
+	zc := NewZencoder("abc", WithBaseURL(srv.URL), WithHooks(Hook{
+		BeforeRequest: func(ctx context.Context, call *CallInfo) error {
+			return abort
+		},
+		OnError: func(ctx context.Context, call *CallInfo, err error) {
+			failed = err
+		},
+	}))
+
+	err := zc.CancelJob(123)
+	var hookErr *HookError
+	if !errors.As(err, &hookErr) || !errors.Is(err, abort) {
+		t.Fatal("Expected the hook error", err)
+	}
+
+	if !errors.Is(failed, abort) {
+		t.Fatal("Expected OnError to run", failed)
+	}
+
+	if requests != 0 {
+		t.Fatal("Expected no request to be sent", requests)
+	}
+
+	// Aborted calls are not retried
+	attempts := 0
+	zc = NewZencoder("abc", WithBaseURL(srv.URL), WithRetryPolicy(&RetryPolicy{MaxAttempts: 4}), WithHooks(Hook{
+		BeforeRequest: func(ctx context.Context, call *CallInfo) error {
+			attempts++
+			return abort
+		},
+	}))
+
+	if _, err := zc.GetJobProgress(123); !errors.Is(err, abort) {
+		t.Fatal("Expected the hook error", err)
+	}
+
+	if attempts != 1 {
+		t.Fatal("Expected a single attempt", attempts)
+	}
+}
+
+func TestHooksRetries(t *testing.T) {
+	calls := 0
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jobs/123/progress.json", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		fmt.Fprintln(w, `{"state": "processing"}`)
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	var attempts []int
+	var statuses []int
+
+	zc := NewZencoder("abc", WithBaseURL(srv.URL), WithRetryPolicy(testRetryPolicy()), WithHooks(Hook{
+		BeforeRequest: func(ctx context.Context, call *CallInfo) error {
+			attempts = append(attempts, call.Attempt)
+			return nil
+		},
+		AfterResponse: func(ctx context.Context, call *CallInfo) {
+			statuses = append(statuses, call.StatusCode)
+		},
+	}))
+
+	if _, err := zc.GetJobProgress(123); err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if len(attempts) != 2 || attempts[0] != 1 || attempts[1] != 2 {
+		t.Fatal("Expected hooks to run per attempt", attempts)
+	}
+
+	if len(statuses) != 2 || statuses[0] != http.StatusServiceUnavailable || statuses[1] != http.StatusOK {
+		t.Fatal("Expected a status per attempt", statuses)
+	}
+}
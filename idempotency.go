@@ -0,0 +1,185 @@
+package zencoder
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// idempotencyMarker precedes the token stamped into a job's PassThrough
+const idempotencyMarker = "zencoder-idempotency-key:"
+
+// idempotencyClockSkew is how much earlier than the first attempt the search
+// for an accepted job goes back, allowing for Zencoder's clock and its
+// timestamps being truncated to the second
+const idempotencyClockSkew = 5 * time.Minute
+
+// IdempotencyOptions configures CreateJobIdempotent
+type IdempotencyOptions struct {
+	Token       string // Token identifying the job; generated if empty
+	MaxAttempts int    // Attempts to create the job, 3 if zero
+}
+
+// CreateJobIdempotent creates a Job without risking a duplicate after an ambiguous failure
+func (z *Zencoder) CreateJobIdempotent(settings *EncodingSettings, opts *IdempotencyOptions) (*CreateJobResponse, error) {
+	return z.CreateJobIdempotentContext(context.Background(), settings, opts)
+}
+
+// CreateJobIdempotentContext creates a Job without risking a duplicate after an
+// ambiguous failure, such as a timeout or a 5xx, where Zencoder may or may not
+// have accepted the request.
+//
+// A token is stamped into the job's PassThrough.  After an ambiguous failure
+// the jobs created since the first attempt are searched for the token, page
+// after page: if a job carries it, its CreateJobResponse is returned,
+// otherwise creation is retried.  The caller's settings are left untouched.
+func (z *Zencoder) CreateJobIdempotentContext(ctx context.Context, settings *EncodingSettings, opts *IdempotencyOptions) (*CreateJobResponse, error) {
+	if settings == nil {
+		return nil, errors.New("zencoder: nil EncodingSettings")
+	}
+
+	var options IdempotencyOptions
+	if opts != nil {
+		options = *opts
+	}
+
+	if options.Token == "" {
+		token, err := newIdempotencyToken()
+		if err != nil {
+			return nil, err
+		}
+		options.Token = token
+	}
+
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = 3
+	}
+
+	policy := z.Retry
+	if policy == nil {
+		policy = DefaultRetryPolicy()
+	}
+
+	stamped := *settings
+	stamped.PassThrough = stampPassThrough(settings.PassThrough, options.Token)
+
+	// Retries are decided here, after searching, never blindly by call()
+	createCtx := withoutRetries(ctx)
+	started := time.Now()
+
+	var err error
+	for attempt := 1; attempt <= options.MaxAttempts; attempt++ {
+		var result *CreateJobResponse
+		result, err = z.CreateJobContext(createCtx, &stamped)
+		if err == nil {
+			return result, nil
+		}
+
+		if !isAmbiguous(ctx, err) {
+			return nil, err
+		}
+
+		// Give Zencoder a moment to list a job it may have just accepted
+		if err := sleep(ctx, policy.backoff(attempt, nil)); err != nil {
+			return nil, err
+		}
+
+		found, searchErr := z.findJobByToken(ctx, options.Token, started.Add(-idempotencyClockSkew))
+		if searchErr != nil {
+			// Retrying without knowing whether the job exists could duplicate it
+			return nil, errors.Join(err, searchErr)
+		}
+
+		if found != nil {
+			return found, nil
+		}
+	}
+
+	return nil, err
+}
+
+// IdempotencyToken returns the token stamped into a job's PassThrough by CreateJobIdempotent
+func IdempotencyToken(passThrough string) (string, bool) {
+	i := strings.LastIndex(passThrough, idempotencyMarker)
+	if i < 0 {
+		return "", false
+	}
+
+	token := passThrough[i+len(idempotencyMarker):]
+	if j := strings.IndexByte(token, ' '); j >= 0 {
+		token = token[:j]
+	}
+
+	return token, token != ""
+}
+
+func stampPassThrough(passThrough, token string) string {
+	stamp := idempotencyMarker + token
+	if passThrough == "" {
+		return stamp
+	}
+
+	return passThrough + " " + stamp
+}
+
+func newIdempotencyToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// isAmbiguous reports whether a failed CreateJob may nevertheless have created the job
+func isAmbiguous(ctx context.Context, err error) bool {
+	// The request was never sent if the circuit was open or a hook aborted it
+	var hookErr *HookError
+	if ctx.Err() != nil || errors.Is(err, ErrCircuitOpen) || errors.As(err, &hookErr) {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= http.StatusInternalServerError
+	}
+
+	// Connection failures, client timeouts and truncated responses
+	return true
+}
+
+// findJobByToken searches the jobs created after since for one stamped with token
+func (z *Zencoder) findJobByToken(ctx context.Context, token string, since time.Time) (*CreateJobResponse, error) {
+	it := z.IterateJobsContext(ctx, &ListJobsOptions{PerPage: defaultJobsPerPage, CreatedAfter: since})
+	for it.Next() {
+		job := it.Job().Job
+		if job == nil || job.PassThrough == nil {
+			continue
+		}
+
+		if found, ok := IdempotencyToken(*job.PassThrough); !ok || found != token {
+			continue
+		}
+
+		result := &CreateJobResponse{
+			Id:   job.Id,
+			Test: job.Test,
+		}
+
+		for _, output := range job.OutputMediaFiles {
+			result.Outputs = append(result.Outputs, CreateJobOutput{
+				Id:    output.OutputID(),
+				Label: output.Label,
+				Url:   output.Url,
+			})
+		}
+
+		return result, nil
+	}
+
+	return nil, it.Err()
+}
@@ -0,0 +1,278 @@
+package zencoder
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeJobsServer accepts jobs and lists them, failing creation as configured
+type fakeJobsServer struct {
+	mu   sync.Mutex
+	jobs []*Job
+
+	posts        int
+	lists        int
+	createStatus func(post int) (status int, created bool)
+	concurrent   int // Jobs created by other clients right after each accepted job
+}
+
+func (f *fakeJobsServer) add(passThrough string) *Job {
+	label := "hd"
+	job := &Job{
+		Id:               JobID(1000 + len(f.jobs)),
+		PassThrough:      &passThrough,
+		CreatedAt:        NewTimestamp(time.Now()),
+		OutputMediaFiles: []*MediaFile{{Id: 2000, Label: &label, Url: "s3://bucket/out.mp4"}},
+	}
+	f.jobs = append([]*Job{job}, f.jobs...)
+
+	return job
+}
+
+func (f *fakeJobsServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jobs", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		f.posts++
+		status, created := f.createStatus(f.posts)
+
+		var settings EncodingSettings
+		json.NewDecoder(r.Body).Decode(&settings)
+
+		if created {
+			f.add(settings.PassThrough)
+			for i := 0; i < f.concurrent; i++ {
+				f.add("")
+			}
+		}
+
+		w.WriteHeader(status)
+		if status == http.StatusCreated {
+			fmt.Fprintf(w, `{"id": %d, "outputs": [{"id": 2000, "label": "hd", "url": "s3://bucket/out.mp4"}]}`, f.jobs[0].Id)
+		}
+	})
+	mux.HandleFunc("/jobs.json", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		f.lists++
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+		if page < 1 {
+			page = 1
+		}
+		if perPage < 1 {
+			perPage = 50
+		}
+
+		list := []*JobDetails{}
+		for i := (page - 1) * perPage; i < len(f.jobs) && i < page*perPage; i++ {
+			list = append(list, &JobDetails{Job: f.jobs[i]})
+		}
+		json.NewEncoder(w).Encode(list)
+	})
+
+	return mux
+}
+
+func TestCreateJobIdempotentFindsAcceptedJob(t *testing.T) {
+	f := &fakeJobsServer{createStatus: func(post int) (int, bool) {
+		// Zencoder accepts the job, but the response is lost
+		return http.StatusBadGateway, true
+	}}
+
+	srv := httptest.NewServer(f.handler())
+	defer srv.Close()
+
+	zc := NewZencoder("abc", WithBaseURL(srv.URL), WithRetryPolicy(testRetryPolicy()))
+	zc.Retry.RetryCreateJob = true
+
+	settings := &EncodingSettings{Input: "s3://bucket/in.mov", PassThrough: "customer-42"}
+	resp, err := zc.CreateJobIdempotent(settings, &IdempotencyOptions{Token: "tok123"})
+	if err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if resp.Id != 1000 {
+		t.Fatal("Expected the accepted job", resp.Id)
+	}
+
+	if len(resp.Outputs) != 1 || resp.Outputs[0].Id != 2000 || *resp.Outputs[0].Label != "hd" {
+		t.Fatal("Expected the accepted job's outputs", resp.Outputs)
+	}
+
+	if f.posts != 1 {
+		t.Fatal("Expected a single creation request", f.posts)
+	}
+
+	if *f.jobs[0].PassThrough != "customer-42 zencoder-idempotency-key:tok123" {
+		t.Fatal("Expected the token in PassThrough", *f.jobs[0].PassThrough)
+	}
+
+	if settings.PassThrough != "customer-42" {
+		t.Fatal("Expected the caller's settings to be left untouched", settings.PassThrough)
+	}
+}
+
+func TestCreateJobIdempotentSearchesEveryPage(t *testing.T) {
+	f := &fakeJobsServer{
+		createStatus: func(post int) (int, bool) {
+			return http.StatusBadGateway, true
+		},
+		concurrent: 60,
+	}
+
+	// Jobs older than the first attempt end the search
+	for i := 0; i < 100; i++ {
+		f.add("").CreatedAt = NewTimestamp(time.Now().Add(-time.Hour))
+	}
+
+	srv := httptest.NewServer(f.handler())
+	defer srv.Close()
+
+	zc := NewZencoder("abc", WithBaseURL(srv.URL), WithRetryPolicy(testRetryPolicy()))
+
+	resp, err := zc.CreateJobIdempotent(&EncodingSettings{}, &IdempotencyOptions{Token: "tok123"})
+	if err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if resp.Id != 1100 {
+		t.Fatal("Expected the accepted job behind the jobs created since", resp.Id)
+	}
+
+	if f.posts != 1 || f.lists != 2 {
+		t.Fatal("Expected a single creation request and two pages", f.posts, f.lists)
+	}
+
+	// The search stops at the first job created before the first attempt
+	f.lists = 0
+	found, err := zc.findJobByToken(context.Background(), "missing", time.Now().Add(-time.Minute))
+	if found != nil || err != nil {
+		t.Fatal("Expected no job", found, err)
+	}
+
+	if f.lists != 2 {
+		t.Fatal("Expected the search to stop at older jobs", f.lists)
+	}
+
+	if _, err := zc.CreateJobIdempotent(nil, nil); err == nil {
+		t.Fatal("Expected an error for nil settings")
+	}
+}
+
+func TestCreateJobIdempotentRetriesRejectedJob(t *testing.T) {
+	f := &fakeJobsServer{createStatus: func(post int) (int, bool) {
+		if post == 1 {
+			return http.StatusServiceUnavailable, false
+		}
+		return http.StatusCreated, true
+	}}
+
+	srv := httptest.NewServer(f.handler())
+	defer srv.Close()
+
+	zc := NewZencoder("abc", WithBaseURL(srv.URL), WithRetryPolicy(testRetryPolicy()))
+
+	resp, err := zc.CreateJobIdempotent(&EncodingSettings{}, nil)
+	if err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if resp.Id != 1000 {
+		t.Fatal("Expected the created job", resp.Id)
+	}
+
+	if f.posts != 2 || f.lists != 1 {
+		t.Fatal("Expected a search then a retry", f.posts, f.lists)
+	}
+
+	token, ok := IdempotencyToken(*f.jobs[0].PassThrough)
+	if !ok || len(token) != 32 {
+		t.Fatal("Expected a generated token", token)
+	}
+}
+
+func TestCreateJobIdempotentDefiniteFailure(t *testing.T) {
+	f := &fakeJobsServer{createStatus: func(post int) (int, bool) {
+		return http.StatusUnprocessableEntity, false
+	}}
+
+	srv := httptest.NewServer(f.handler())
+	defer srv.Close()
+
+	zc := NewZencoder("abc", WithBaseURL(srv.URL), WithRetryPolicy(testRetryPolicy()))
+
+	_, err := zc.CreateJobIdempotent(&EncodingSettings{}, nil)
+	if !errors.Is(err, ErrValidation) {
+		t.Fatal("Expected ErrValidation", err)
+	}
+
+	if f.posts != 1 || f.lists != 0 {
+		t.Fatal("Expected no search and no retry", f.posts, f.lists)
+	}
+
+	// Ambiguous failures are given up on after MaxAttempts
+	f.createStatus = func(post int) (int, bool) {
+		return http.StatusInternalServerError, false
+	}
+	f.posts = 0
+
+	_, err = zc.CreateJobIdempotent(&EncodingSettings{}, &IdempotencyOptions{MaxAttempts: 2})
+	if err == nil {
+		t.Fatal("Expected error")
+	}
+
+	if f.posts != 2 {
+		t.Fatal("Expected 2 attempts", f.posts)
+	}
+
+	// A call aborted by a hook was never sent, so there is nothing to search for
+	f.posts, f.lists = 0, 0
+	abort := errors.New("abort")
+	zc.Use(Hook{BeforeRequest: func(ctx context.Context, call *CallInfo) error {
+		return abort
+	}})
+
+	_, err = zc.CreateJobIdempotent(&EncodingSettings{}, nil)
+	if !errors.Is(err, abort) {
+		t.Fatal("Expected the hook error", err)
+	}
+
+	if f.posts != 0 || f.lists != 0 {
+		t.Fatal("Expected no request", f.posts, f.lists)
+	}
+}
+
+func TestIdempotencyToken(t *testing.T) {
+	tests := map[string]string{
+		"zencoder-idempotency-key:abc":                "abc",
+		"customer-42 zencoder-idempotency-key:abc":    "abc",
+		"zencoder-idempotency-key:abc trailing words": "abc",
+	}
+
+	for passThrough, expected := range tests {
+		if token, ok := IdempotencyToken(passThrough); !ok || token != expected {
+			t.Fatal("Unexpected token", passThrough, token)
+		}
+	}
+
+	if _, ok := IdempotencyToken("customer-42"); ok {
+		t.Fatal("Expected no token")
+	}
+
+	if stamp := stampPassThrough("", "abc"); !strings.HasPrefix(stamp, idempotencyMarker) {
+		t.Fatal("Expected a bare stamp", stamp)
+	}
+}
@@ -0,0 +1,155 @@
+package zencoder
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidID = errors.New("zencoder: invalid id")
+
+// JobID identifies a Job
+type JobID int64
+
+// InputID identifies an input media file
+type InputID int64
+
+// OutputID identifies an output media file
+type OutputID int64
+
+// ParseJobID parses a job ID given as a number, e.g. "1234", a dashboard URL,
+// e.g. "https://app.zencoder.com/jobs/1234", or an API URL, e.g.
+// "https://app.zencoder.com/api/v2/jobs/1234.json"
+func ParseJobID(s string) (JobID, error) {
+	id, err := parseID(s, "jobs")
+	return JobID(id), err
+}
+
+// ParseInputID parses an input ID given as a number or a dashboard or API URL
+func ParseInputID(s string) (InputID, error) {
+	id, err := parseID(s, "inputs")
+	return InputID(id), err
+}
+
+// ParseOutputID parses an output ID given as a number or a dashboard or API URL
+func ParseOutputID(s string) (OutputID, error) {
+	id, err := parseID(s, "outputs")
+	return OutputID(id), err
+}
+
+func (id JobID) String() string    { return strconv.FormatInt(int64(id), 10) }
+func (id InputID) String() string  { return strconv.FormatInt(int64(id), 10) }
+func (id OutputID) String() string { return strconv.FormatInt(int64(id), 10) }
+
+func (id *JobID) UnmarshalText(b []byte) (err error) {
+	*id, err = ParseJobID(string(b))
+	return
+}
+
+func (id *InputID) UnmarshalText(b []byte) (err error) {
+	*id, err = ParseInputID(string(b))
+	return
+}
+
+func (id *OutputID) UnmarshalText(b []byte) (err error) {
+	*id, err = ParseOutputID(string(b))
+	return
+}
+
+// UnmarshalJSON accepts the ID as a JSON number or string
+func (id *JobID) UnmarshalJSON(b []byte) error {
+	v, err := unmarshalID(b, "jobs")
+	*id = JobID(v)
+	return err
+}
+
+// UnmarshalJSON accepts the ID as a JSON number or string
+func (id *InputID) UnmarshalJSON(b []byte) error {
+	v, err := unmarshalID(b, "inputs")
+	*id = InputID(v)
+	return err
+}
+
+// UnmarshalJSON accepts the ID as a JSON number or string
+func (id *OutputID) UnmarshalJSON(b []byte) error {
+	v, err := unmarshalID(b, "outputs")
+	*id = OutputID(v)
+	return err
+}
+
+// InputID returns the ID of an input media file
+func (m *MediaFile) InputID() InputID {
+	return InputID(m.Id)
+}
+
+// OutputID returns the ID of an output media file
+func (m *MediaFile) OutputID() OutputID {
+	return OutputID(m.Id)
+}
+
+// InputID returns the ID of the input whose progress this is
+func (p *FileProgress) InputID() InputID {
+	return InputID(p.Id)
+}
+
+// OutputID returns the ID of the output whose progress this is
+func (p *FileProgress) OutputID() OutputID {
+	return OutputID(p.Id)
+}
+
+// InputID returns the ID of the input this error belongs to
+func (e *MediaFileError) InputID() InputID {
+	return InputID(e.Id)
+}
+
+// OutputID returns the ID of the output this error belongs to
+func (e *MediaFileError) OutputID() OutputID {
+	return OutputID(e.Id)
+}
+
+func unmarshalID(b []byte, collection string) (int64, error) {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		var n int64
+		err := json.Unmarshal(b, &n)
+		return n, err
+	}
+
+	if s == "" {
+		return 0, nil
+	}
+
+	return parseID(s, collection)
+}
+
+// parseID parses a positive ID given as a number or as a URL whose path holds
+// it after the collection segment, e.g. ".../outputs/1234.json"
+func parseID(s, collection string) (int64, error) {
+	value := strings.TrimSpace(s)
+
+	if strings.Contains(value, "/") {
+		u, err := url.Parse(value)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %q", ErrInvalidID, s)
+		}
+
+		segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+		value = ""
+		for i := len(segments) - 2; i >= 0; i-- {
+			if segments[i] == collection {
+				value = strings.TrimSuffix(segments[i+1], ".json")
+				break
+			}
+		}
+	}
+
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidID, s)
+	}
+
+	return id, nil
+}
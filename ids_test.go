@@ -0,0 +1,101 @@
+package zencoder
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseIDs(t *testing.T) {
+	jobs := map[string]JobID{
+		"1234":                               1234,
+		" 1234":                              1234,
+		"https://app.zencoder.com/jobs/1234": 1234,
+		"https://app.zencoder.com/api/v2/jobs/1234.json":          1234,
+		"https://app.zencoder.com/api/v2/jobs/1234/progress.json": 1234,
+		"jobs/1234/cancel.json":                                   1234,
+	}
+
+	for s, expected := range jobs {
+		if id, err := ParseJobID(s); err != nil || id != expected {
+			t.Fatal("Unexpected job id", s, id, err)
+		}
+	}
+
+	if id, err := ParseInputID("https://app.zencoder.com/api/v2/inputs/4294967296.json"); err != nil || id != 4294967296 {
+		t.Fatal("Expected an input id beyond int32", id, err)
+	}
+
+	if id, err := ParseOutputID("https://app.zencoder.com/jobs/1234/outputs/5678"); err != nil || id != 5678 {
+		t.Fatal("Unexpected output id", id, err)
+	}
+
+	for _, s := range []string{"", "abc", "-1", "0", "https://app.zencoder.com/jobs/", "https://app.zencoder.com/outputs/5678"} {
+		if _, err := ParseJobID(s); !errors.Is(err, ErrInvalidID) {
+			t.Fatal("Expected ErrInvalidID", s, err)
+		}
+	}
+
+	if s := JobID(1234).String(); s != "1234" {
+		t.Fatal("Unexpected String", s)
+	}
+
+	var output OutputID
+	if err := output.UnmarshalText([]byte("outputs/42.json")); err != nil || output != 42 {
+		t.Fatal("Unexpected output id", output, err)
+	}
+}
+
+func TestIDsJSON(t *testing.T) {
+	var file OutputMediaFile
+	if err := json.Unmarshal([]byte(`{"id": 5678, "job_id": "1234", "state": "failed"}`), &file); err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if file.JobId != 1234 || file.OutputID() != 5678 {
+		t.Fatal("Expected ids given as numbers or strings", file.JobId, file.OutputID())
+	}
+
+	if errs := file.Errors(); len(errs) != 1 || errs[0].OutputID() != 5678 {
+		t.Fatal("Expected the error of the output", errs)
+	}
+
+	b, err := json.Marshal(&CreateJobResponse{Id: 1234, Outputs: []CreateJobOutput{{Id: 5678}}})
+	if err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if string(b) != `{"id":1234,"outputs":[{"id":5678}]}` {
+		t.Fatal("Expected ids encoded as numbers", string(b))
+	}
+
+	var job Job
+	if err := json.Unmarshal([]byte(`{"id": "abc"}`), &job); !errors.Is(err, ErrInvalidID) {
+		t.Fatal("Expected ErrInvalidID", err)
+	}
+}
+
+func TestGetInputDetailsLargeID(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/inputs/4294967296.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"id": 4294967296, "job_id": 4294967297}`)
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	zc := NewZencoder("abc", WithBaseURL(srv.URL))
+
+	id, _ := ParseInputID("https://app.zencoder.com/api/v2/inputs/4294967296.json")
+	details, err := zc.GetInputDetails(id)
+	if err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if details.InputID() != id || details.JobId != 4294967297 {
+		t.Fatal("Unexpected ids", details.InputID(), details.JobId)
+	}
+}
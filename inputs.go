@@ -1,14 +1,24 @@
 package zencoder
 
 import (
+	"context"
 	"fmt"
 )
 
 // Get Input Details
-func (z *Zencoder) GetInputDetails(id int32) (*InputMediaFile, error) {
+func (z *Zencoder) GetInputDetails(id InputID) (*InputMediaFile, error) {
+	return z.GetInputDetailsContext(context.Background(), id)
+}
+
+// Get Input Details with a Context
+func (z *Zencoder) GetInputDetailsContext(ctx context.Context, id InputID) (*InputMediaFile, error) {
 	var details InputMediaFile
 
-	if err := z.getBody(fmt.Sprintf("inputs/%d.json", id), &details); err != nil {
+	cacheable := func() (JobID, bool) {
+		return details.JobId, details.State.IsTerminal()
+	}
+
+	if err := z.getCached(ctx, fmt.Sprintf("inputs/%d.json", id), &details, cacheable); err != nil {
 		return nil, err
 	}
 
@@ -16,10 +26,15 @@ func (z *Zencoder) GetInputDetails(id int32) (*InputMediaFile, error) {
 }
 
 // Input Progress
-func (z *Zencoder) GetInputProgress(id int32) (*FileProgress, error) {
+func (z *Zencoder) GetInputProgress(id InputID) (*FileProgress, error) {
+	return z.GetInputProgressContext(context.Background(), id)
+}
+
+// Input Progress with a Context
+func (z *Zencoder) GetInputProgressContext(ctx context.Context, id InputID) (*FileProgress, error) {
 	var details FileProgress
 
-	if err := z.getBody(fmt.Sprintf("inputs/%d/progress.json", id), &details); err != nil {
+	if err := z.getBody(ctx, fmt.Sprintf("inputs/%d/progress.json", id), &details); err != nil {
 		return nil, err
 	}
 
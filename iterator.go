@@ -0,0 +1,82 @@
+package zencoder
+
+import (
+	"context"
+)
+
+// JobIterator walks the jobs of an account, most recent first, fetching
+// successive pages as needed:
+//
+//	it := zc.IterateJobs(nil)
+//	for it.Next() {
+//		log.Println(it.Job().Job.Id)
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+//
+// Iteration may be stopped at any point.  A JobIterator is not safe for concurrent use.
+type JobIterator struct {
+	z       *Zencoder
+	ctx     context.Context
+	options ListJobsOptions
+
+	jobs []*JobDetails
+	job  *JobDetails
+	last bool
+	err  error
+}
+
+// Iterate over Jobs, starting at opts.Page
+func (z *Zencoder) IterateJobs(opts *ListJobsOptions) *JobIterator {
+	return z.IterateJobsContext(context.Background(), opts)
+}
+
+// Iterate over Jobs with a Context, starting at opts.Page
+func (z *Zencoder) IterateJobsContext(ctx context.Context, opts *ListJobsOptions) *JobIterator {
+	it := &JobIterator{z: z, ctx: ctx}
+	if opts != nil {
+		it.options = *opts
+	}
+
+	if it.options.Page < 1 {
+		it.options.Page = 1
+	}
+
+	return it
+}
+
+// Next advances to the next job, returning false when there are no more jobs
+// or a page could not be fetched
+func (it *JobIterator) Next() bool {
+	it.job = nil
+
+	for len(it.jobs) == 0 {
+		if it.last || it.err != nil {
+			return false
+		}
+
+		page, err := it.z.ListJobsPageContext(it.ctx, &it.options)
+		if err != nil {
+			it.err = err
+			return false
+		}
+
+		it.jobs = page.Jobs
+		it.last = !page.HasMore
+		it.options.Page++
+	}
+
+	it.job, it.jobs = it.jobs[0], it.jobs[1:]
+	return true
+}
+
+// Job returns the current job
+func (it *JobIterator) Job() *JobDetails {
+	return it.job
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *JobIterator) Err() error {
+	return it.err
+}
@@ -0,0 +1,35 @@
+//go:build go1.23
+
+package zencoder
+
+import (
+	"context"
+	"iter"
+)
+
+// Jobs returns an iterator over the jobs of an account, most recent first,
+// for use with range:
+//
+//	for job, err := range zc.Jobs(ctx, nil) {
+//		if err != nil {
+//			return err
+//		}
+//		log.Println(job.Job.Id)
+//	}
+//
+// Pages are fetched as the loop advances.  If a page cannot be fetched, the
+// error is yielded once, after the jobs already yielded, and iteration ends.
+func (z *Zencoder) Jobs(ctx context.Context, opts *ListJobsOptions) iter.Seq2[*JobDetails, error] {
+	return func(yield func(*JobDetails, error) bool) {
+		it := z.IterateJobsContext(ctx, opts)
+		for it.Next() {
+			if !yield(it.Job(), nil) {
+				return
+			}
+		}
+
+		if err := it.Err(); err != nil {
+			yield(nil, err)
+		}
+	}
+}
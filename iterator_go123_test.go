@@ -0,0 +1,42 @@
+//go:build go1.23
+
+package zencoder
+
+import (
+	"context"
+	"testing"
+)
+
+func TestJobsRange(t *testing.T) {
+	var pages []int
+	srv := pagedJobsServer(7, 3, &pages)
+	defer srv.Close()
+
+	zc := NewZencoder("abc", WithBaseURL(srv.URL))
+
+	var ids []JobID
+	var errs []error
+	for job, err := range zc.Jobs(context.Background(), &ListJobsOptions{PerPage: 3}) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		ids = append(ids, job.Job.Id)
+	}
+
+	if len(ids) != 6 || len(errs) != 1 {
+		t.Fatal("Expected the jobs of two pages, then the error", ids, errs)
+	}
+
+	// Breaking out of the loop stops fetching pages
+	pages = nil
+	for job := range zc.Jobs(context.Background(), &ListJobsOptions{PerPage: 3}) {
+		if job.Job.Id == 5 {
+			break
+		}
+	}
+
+	if len(pages) != 1 {
+		t.Fatal("Expected a single page", pages)
+	}
+}
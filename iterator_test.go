@@ -0,0 +1,108 @@
+package zencoder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// pagedJobsServer lists total jobs, most recent first, failing on failPage if set
+func pagedJobsServer(total, failPage int, pages *[]int) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jobs.json", func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page == 0 {
+			page = 1
+		}
+		perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+		*pages = append(*pages, page)
+
+		if page == failPage {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		fmt.Fprint(w, "[")
+		for i := 0; i < perPage; i++ {
+			id := total - (page-1)*perPage - i
+			if id <= 0 {
+				break
+			}
+			if i > 0 {
+				fmt.Fprint(w, ",")
+			}
+			fmt.Fprintf(w, `{"job": {"id": %d}}`, id)
+		}
+		fmt.Fprint(w, "]")
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func TestJobIterator(t *testing.T) {
+	var pages []int
+	srv := pagedJobsServer(7, 0, &pages)
+	defer srv.Close()
+
+	zc := NewZencoder("abc", WithBaseURL(srv.URL))
+
+	var ids []JobID
+	it := zc.IterateJobs(&ListJobsOptions{PerPage: 3})
+	for it.Next() {
+		ids = append(ids, it.Job().Job.Id)
+	}
+
+	if it.Err() != nil {
+		t.Fatal("Expected no error", it.Err())
+	}
+
+	if len(ids) != 7 || ids[0] != 7 || ids[6] != 1 {
+		t.Fatal("Expected every job", ids)
+	}
+
+	if len(pages) != 3 {
+		t.Fatal("Expected 3 pages", pages)
+	}
+
+	// Pages are fetched lazily
+	pages = nil
+	it = zc.IterateJobs(&ListJobsOptions{PerPage: 3})
+	if !it.Next() || it.Job().Job.Id != 7 {
+		t.Fatal("Expected the first job", it.Job())
+	}
+
+	if len(pages) != 1 {
+		t.Fatal("Expected a single page", pages)
+	}
+}
+
+func TestJobIteratorError(t *testing.T) {
+	var pages []int
+	srv := pagedJobsServer(7, 2, &pages)
+	defer srv.Close()
+
+	zc := NewZencoder("abc", WithBaseURL(srv.URL))
+
+	var ids []JobID
+	it := zc.IterateJobsContext(context.Background(), &ListJobsOptions{PerPage: 3})
+	for it.Next() {
+		ids = append(ids, it.Job().Job.Id)
+	}
+
+	if len(ids) != 3 {
+		t.Fatal("Expected the jobs of the first page", ids)
+	}
+
+	var apiErr *APIError
+	if !errors.As(it.Err(), &apiErr) || apiErr.StatusCode != http.StatusInternalServerError {
+		t.Fatal("Expected an APIError", it.Err())
+	}
+
+	if it.Next() {
+		t.Fatal("Expected the iteration to stay stopped")
+	}
+}
@@ -1,7 +1,12 @@
 package zencoder
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"net/url"
+	"strconv"
+	"time"
 )
 
 const (
@@ -9,38 +14,44 @@ const (
 	UnknownError      = "UnknownError"
 )
 
+// Progress of an input or output media file
 type FileProgress struct {
-	Id                   int64   `json:"id,omitempty"`
-	State                string  `json:"state,omitempty"`
-	CurrentEvent         string  `json:"current_event,omitempty"`
-	CurrentEventProgress float64 `json:"current_event_progress,omitempty"`
-	OverallProgress      float64 `json:"progress,omitempty"`
+	Id                   int64     `json:"id,omitempty"` // Input or output ID, see InputID and OutputID
+	State                FileState `json:"state,omitempty"`
+	CurrentEvent         string    `json:"current_event,omitempty"`
+	CurrentEventProgress float64   `json:"current_event_progress,omitempty"`
+	OverallProgress      float64   `json:"progress,omitempty"`
 }
 
 type JobProgress struct {
-	State          string          `json:"state,omitempty"`
+	State          JobState        `json:"state,omitempty"`
 	JobProgress    float64         `json:"progress,omitempty"`
 	InputProgress  *FileProgress   `json:"input,omitempty"`
 	OutputProgress []*FileProgress `json:"outputs,omitempty"`
 }
 
+// Output in a CreateJobResponse
+type CreateJobOutput struct {
+	Id    OutputID `json:"id,omitempty"`
+	Label *string  `json:"label,omitempty"`
+	Url   string   `json:"url,omitempty"`
+}
+
 // Response from CreateJob
 type CreateJobResponse struct {
-	Id      int64 `json:"id,omitempty"`
-	Test    bool  `json:"test,omitempty"`
-	Outputs []struct {
-		Id    int64   `json:"id,omitempty"`
-		Label *string `json:"label,omitempty"`
-		Url   string  `json:"url,omitempty"`
-	} `json:"outputs,omitempty"`
+	Id      JobID             `json:"id,omitempty"`
+	Test    bool              `json:"test,omitempty"`
+	Outputs []CreateJobOutput `json:"outputs,omitempty"`
 }
 
-// A MediaFile
+// A MediaFile is either an input or an output; its Id is an int64 rather
+// than an InputID or OutputID because the struct is shared by both, and is
+// read as one or the other with InputID and OutputID.
 type MediaFile struct {
 	Id                 int64        `json:"id,omitempty"`
 	Url                string       `json:"url,omitempty"`
 	Label              *string      `json:"label,omitempty"`
-	State              string       `json:"state,omitempty"`
+	State              FileState    `json:"state,omitempty"`
 	Format             string       `json:"format,omitempty"`
 	Type               string       `json:"type,omitempty"`
 	FrameRate          float64      `json:"frame_rate,omitempty"`
@@ -59,9 +70,9 @@ type MediaFile struct {
 	Thumbnails         []*Thumbnail `json:"thumbnails,omitempty"`
 	MD5Checksum        string       `json:"md5_checksum,omitempty"`
 	Privacy            bool         `json:"privacy"`
-	CreatedAt          string       `json:"created_at,omitempty"`
-	FinishedAt         string       `json:"finished_at,omitempty"`
-	UpdatedAt          string       `json:"updated_at,omitempty"`
+	CreatedAt          Timestamp    `json:"created_at,omitempty"`
+	FinishedAt         Timestamp    `json:"finished_at,omitempty"`
+	UpdatedAt          Timestamp    `json:"updated_at,omitempty"`
 	Test               bool         `json:"test,omitempty"`
 
 	// Errors
@@ -70,11 +81,14 @@ type MediaFile struct {
 	ErrorLink                 *string `json:"error_link,omitempty"`
 	PrimaryUploadErrorMessage *string `json:"primary_upload_error_message,omitempty"`
 	PrimaryUploadErrorLink    *string `json:"primary_upload_error_link,omitempty"`
+
+	// Fields not known to this package, preserved when re-marshalling
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
-// MediaFileError
+// MediaFileError is an error of an input or output media file
 type MediaFileError struct {
-	Id           int64   `json:"id,omitempty"`
+	Id           int64   `json:"id,omitempty"` // Input or output ID, see InputID and OutputID
 	ErrorMessage *string `json:"error_message,omitempty"`
 	ErrorClass   *string `json:"error_class,omitempty"`
 	ErrorLink    *string `json:"error_link,omitempty"`
@@ -82,12 +96,12 @@ type MediaFileError struct {
 
 type InputMediaFile struct {
 	MediaFile
-	JobId int64 `json:"job_id,omitempty"`
+	JobId JobID `json:"job_id,omitempty"`
 }
 
 type OutputMediaFile struct {
 	MediaFile
-	JobId int64 `json:"job_id,omitempty"`
+	JobId JobID `json:"job_id,omitempty"`
 }
 
 // A Thumbnail
@@ -96,8 +110,8 @@ type Thumbnail struct {
 	Url       string            `json:"url,omitempty"`
 	Label     string            `json:"label,omitempty"`
 	Images    []*ThumbnailImage `json:"images,omitempty"`
-	CreatedAt string            `json:"created_at,omitempty"`
-	UpdatedAt string            `json:"updated_at,omitempty"`
+	CreatedAt Timestamp         `json:"created_at,omitempty"`
+	UpdatedAt Timestamp         `json:"updated_at,omitempty"`
 }
 
 type ThumbnailImage struct {
@@ -109,17 +123,131 @@ type ThumbnailImage struct {
 
 // A Job
 type Job struct {
-	Id               int64        `json:"id,omitempty"`
+	Id               JobID        `json:"id,omitempty"`
 	PassThrough      *string      `json:"pass_through,omitempty"`
-	State            string       `json:"state,omitempty"`
+	State            JobState     `json:"state,omitempty"`
 	InputMediaFile   *MediaFile   `json:"input_media_file,omitempty"`
 	Test             bool         `json:"test,omitempty"`
 	OutputMediaFiles []*MediaFile `json:"output_media_files,omitempty"`
 	Thumbnails       []*Thumbnail `json:"thumbnails,omitempty"`
-	CreatedAt        string       `json:"created_at,omitempty"`
-	FinishedAt       string       `json:"finished_at,omitempty"`
-	UpdatedAt        string       `json:"updated_at,omitempty"`
-	SubmittedAt      string       `json:"submitted_at,omitempty"`
+	CreatedAt        Timestamp    `json:"created_at,omitempty"`
+	FinishedAt       Timestamp    `json:"finished_at,omitempty"`
+	UpdatedAt        Timestamp    `json:"updated_at,omitempty"`
+	SubmittedAt      Timestamp    `json:"submitted_at,omitempty"`
+
+	// Fields not known to this package, preserved when re-marshalling
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+type jobFields Job
+
+func (j *Job) UnmarshalJSON(b []byte) (err error) {
+	j.Extra, err = decodeExtra(b, (*jobFields)(j))
+	return
+}
+
+func (j Job) MarshalJSON() ([]byte, error) {
+	return encodeExtra(jobFields(j), j.Extra)
+}
+
+type mediaFileFields MediaFile
+
+func (m *MediaFile) UnmarshalJSON(b []byte) (err error) {
+	m.Extra, err = decodeExtra(b, (*mediaFileFields)(m))
+	return
+}
+
+func (m MediaFile) MarshalJSON() ([]byte, error) {
+	return encodeExtra(mediaFileFields(m), m.Extra)
+}
+
+// jobIdField is the field InputMediaFile and OutputMediaFile add to MediaFile
+type jobIdField struct {
+	JobId JobID `json:"job_id,omitempty"`
+}
+
+func (m *InputMediaFile) UnmarshalJSON(b []byte) error {
+	return unmarshalWithJobId(b, &m.MediaFile, &m.JobId)
+}
+
+func (m InputMediaFile) MarshalJSON() ([]byte, error) {
+	return encodeExtra(mediaFileFields(m.MediaFile), withJobId(m.Extra, m.JobId))
+}
+
+func (m *OutputMediaFile) UnmarshalJSON(b []byte) error {
+	return unmarshalWithJobId(b, &m.MediaFile, &m.JobId)
+}
+
+func (m OutputMediaFile) MarshalJSON() ([]byte, error) {
+	return encodeExtra(mediaFileFields(m.MediaFile), withJobId(m.Extra, m.JobId))
+}
+
+func unmarshalWithJobId(b []byte, m *MediaFile, jobId *JobID) error {
+	var field jobIdField
+	if err := json.Unmarshal(b, &field); err != nil {
+		return err
+	}
+
+	if err := m.UnmarshalJSON(b); err != nil {
+		return err
+	}
+
+	*jobId = field.JobId
+	delete(m.Extra, "job_id")
+	if len(m.Extra) == 0 {
+		m.Extra = nil
+	}
+
+	return nil
+}
+
+func withJobId(extra map[string]json.RawMessage, jobId JobID) map[string]json.RawMessage {
+	b, _ := json.Marshal(jobIdField{JobId: jobId})
+
+	var fields map[string]json.RawMessage
+	json.Unmarshal(b, &fields)
+	for key, value := range extra {
+		fields[key] = value
+	}
+
+	return fields
+}
+
+// Options for ListJobsPage
+type ListJobsOptions struct {
+	Page         int       // Page to list, starting at 1; 1 if zero
+	PerPage      int       // Jobs per page, at most 50; Zencoder's default if zero
+	State        JobState  // Only list jobs in this state, e.g. JobStateFinished
+	CreatedAfter time.Time // Only list jobs created after this time; applied client-side
+}
+
+// A page of jobs, most recent first
+type JobPage struct {
+	Jobs    []*JobDetails
+	Page    int  // Page number, starting at 1
+	HasMore bool // Whether a following page may hold more jobs
+}
+
+// defaultJobsPerPage is the page size Zencoder uses when none is requested
+const defaultJobsPerPage = 50
+
+func (o *ListJobsOptions) query() string {
+	query := make(url.Values)
+	if o.Page > 1 {
+		query.Set("page", strconv.Itoa(o.Page))
+	}
+	if o.PerPage > 0 {
+		query.Set("per_page", strconv.Itoa(o.PerPage))
+	}
+	if o.State != "" {
+		query.Set("state", string(o.State))
+	}
+
+	if len(query) > 0 {
+		return "jobs.json?" + query.Encode()
+	}
+
+	return "jobs.json"
 }
 
 // Job Details wrapper
@@ -154,7 +282,7 @@ func (m *MediaFile) Errors() (mediaFileErrors []*MediaFileError) {
 	}
 
 	// Unknown error
-	if m.State == "failed" && !hasGeneralErr && !hasUploadErr {
+	if m.State == FileStateFailed && !hasGeneralErr && !hasUploadErr {
 		errClass := UnknownError
 		errMsg := "Status failed, but the usual Zencoder error fields are empty"
 		mediaFileErrors = append(mediaFileErrors, &MediaFileError{
@@ -169,9 +297,14 @@ func (m *MediaFile) Errors() (mediaFileErrors []*MediaFileError) {
 
 // Create a Job
 func (z *Zencoder) CreateJob(settings *EncodingSettings) (*CreateJobResponse, error) {
+	return z.CreateJobContext(context.Background(), settings)
+}
+
+// Create a Job with a Context
+func (z *Zencoder) CreateJobContext(ctx context.Context, settings *EncodingSettings) (*CreateJobResponse, error) {
 	var result CreateJobResponse
 
-	if err := z.post("jobs", settings, &result); err != nil {
+	if err := z.post(ctx, "jobs", settings, &result); err != nil {
 		return nil, err
 	}
 
@@ -180,20 +313,84 @@ func (z *Zencoder) CreateJob(settings *EncodingSettings) (*CreateJobResponse, er
 
 // List Jobs
 func (z *Zencoder) ListJobs() ([]*JobDetails, error) {
+	return z.ListJobsContext(context.Background())
+}
+
+// List Jobs with a Context
+func (z *Zencoder) ListJobsContext(ctx context.Context) ([]*JobDetails, error) {
 	var result []*JobDetails
 
-	if err := z.getBody("jobs.json", &result); err != nil {
+	if err := z.getBody(ctx, "jobs.json", &result); err != nil {
 		return nil, err
 	}
 
 	return result, nil
 }
 
+// List a page of Jobs
+func (z *Zencoder) ListJobsPage(opts *ListJobsOptions) (*JobPage, error) {
+	return z.ListJobsPageContext(context.Background(), opts)
+}
+
+// List a page of Jobs with a Context.  A page is full when it holds PerPage
+// jobs before the CreatedAfter filter is applied, and only a full page may be
+// followed by another one; jobs are listed most recent first, so the listing
+// also ends at the first job created before CreatedAfter.
+func (z *Zencoder) ListJobsPageContext(ctx context.Context, opts *ListJobsOptions) (*JobPage, error) {
+	var options ListJobsOptions
+	if opts != nil {
+		options = *opts
+	}
+
+	if options.Page < 1 {
+		options.Page = 1
+	}
+
+	var result []*JobDetails
+
+	if err := z.getBody(ctx, options.query(), &result); err != nil {
+		return nil, err
+	}
+
+	perPage := options.PerPage
+	if perPage <= 0 || perPage > defaultJobsPerPage {
+		perPage = defaultJobsPerPage
+	}
+
+	page := &JobPage{
+		Page:    options.Page,
+		HasMore: len(result) >= perPage,
+	}
+
+	for _, details := range result {
+		if !options.CreatedAfter.IsZero() && details.Job != nil {
+			created, err := details.Job.CreatedAt.Parse()
+			if err == nil && !created.After(options.CreatedAfter) {
+				page.HasMore = false
+				continue
+			}
+		}
+
+		page.Jobs = append(page.Jobs, details)
+	}
+
+	return page, nil
+}
+
 // Get Job Details
-func (z *Zencoder) GetJobDetails(id int64) (*JobDetails, error) {
+func (z *Zencoder) GetJobDetails(id JobID) (*JobDetails, error) {
+	return z.GetJobDetailsContext(context.Background(), id)
+}
+
+// Get Job Details with a Context
+func (z *Zencoder) GetJobDetailsContext(ctx context.Context, id JobID) (*JobDetails, error) {
 	var result JobDetails
 
-	if err := z.getBody(fmt.Sprintf("jobs/%d.json", id), &result); err != nil {
+	cacheable := func() (JobID, bool) {
+		return id, result.Job != nil && result.Job.State.IsTerminal()
+	}
+
+	if err := z.getCached(ctx, fmt.Sprintf("jobs/%d.json", id), &result, cacheable); err != nil {
 		return nil, err
 	}
 
@@ -201,10 +398,15 @@ func (z *Zencoder) GetJobDetails(id int64) (*JobDetails, error) {
 }
 
 // Job Progress
-func (z *Zencoder) GetJobProgress(id int64) (*JobProgress, error) {
+func (z *Zencoder) GetJobProgress(id JobID) (*JobProgress, error) {
+	return z.GetJobProgressContext(context.Background(), id)
+}
+
+// Job Progress with a Context
+func (z *Zencoder) GetJobProgressContext(ctx context.Context, id JobID) (*JobProgress, error) {
 	var result JobProgress
 
-	if err := z.getBody(fmt.Sprintf("jobs/%d/progress.json", id), &result); err != nil {
+	if err := z.getBody(ctx, fmt.Sprintf("jobs/%d/progress.json", id), &result); err != nil {
 		return nil, err
 	}
 
@@ -212,16 +414,36 @@ func (z *Zencoder) GetJobProgress(id int64) (*JobProgress, error) {
 }
 
 // Resubmit a Job
-func (z *Zencoder) ResubmitJob(id int64) error {
-	return z.putNoContent(fmt.Sprintf("jobs/%d/resubmit.json", id))
+func (z *Zencoder) ResubmitJob(id JobID) error {
+	return z.ResubmitJobContext(context.Background(), id)
+}
+
+// Resubmit a Job with a Context
+func (z *Zencoder) ResubmitJobContext(ctx context.Context, id JobID) error {
+	if z.Cache != nil {
+		// Invalidate even on failure: the job may have been resubmitted anyway
+		defer z.Cache.InvalidateJob(id)
+	}
+
+	return z.putNoContent(ctx, fmt.Sprintf("jobs/%d/resubmit.json", id))
 }
 
 // Cancel a Job
-func (z *Zencoder) CancelJob(id int64) error {
-	return z.putNoContent(fmt.Sprintf("jobs/%d/cancel.json", id))
+func (z *Zencoder) CancelJob(id JobID) error {
+	return z.CancelJobContext(context.Background(), id)
+}
+
+// Cancel a Job with a Context
+func (z *Zencoder) CancelJobContext(ctx context.Context, id JobID) error {
+	return z.putNoContent(ctx, fmt.Sprintf("jobs/%d/cancel.json", id))
 }
 
 // Finish a Live Job
-func (z *Zencoder) FinishLiveJob(id int64) error {
-	return z.putNoContent(fmt.Sprintf("jobs/%d/finish", id))
+func (z *Zencoder) FinishLiveJob(id JobID) error {
+	return z.FinishLiveJobContext(context.Background(), id)
+}
+
+// Finish a Live Job with a Context
+func (z *Zencoder) FinishLiveJobContext(ctx context.Context, id JobID) error {
+	return z.putNoContent(ctx, fmt.Sprintf("jobs/%d/finish", id))
 }
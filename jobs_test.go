@@ -1,10 +1,13 @@
 package zencoder
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestCreateJob(t *testing.T) {
@@ -927,3 +930,91 @@ func TestFinishLiveJob(t *testing.T) {
 		t.Fatal("Expected error")
 	}
 }
+
+func TestGetJobProgressContext(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jobs/123/progress.json", func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	zc := NewZencoder("abc")
+	zc.BaseUrl = srv.URL
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	progress, err := zc.GetJobProgressContext(ctx, 123)
+	if err == nil {
+		t.Fatal("Expected error")
+	}
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal("Expected context.DeadlineExceeded", err)
+	}
+
+	if progress != nil {
+		t.Fatal("Expected no progress", progress)
+	}
+}
+
+func TestListJobsPage(t *testing.T) {
+	var query string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jobs.json", func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		fmt.Fprintln(w, `[
+  {"job": {"id": 3, "state": "finished", "created_at": "2010-01-03T00:00:00Z"}},
+  {"job": {"id": 2, "state": "finished", "created_at": "2010-01-02T00:00:00Z"}},
+  {"job": {"id": 1, "state": "finished", "created_at": "2010-01-01T00:00:00Z"}}
+]`)
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	zc := NewZencoder("abc", WithBaseURL(srv.URL))
+
+	page, err := zc.ListJobsPage(&ListJobsOptions{Page: 2, PerPage: 3, State: "finished"})
+	if err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if query != "page=2&per_page=3&state=finished" {
+		t.Fatal("Unexpected query", query)
+	}
+
+	if page.Page != 2 || len(page.Jobs) != 3 || !page.HasMore {
+		t.Fatal("Expected a full page", page)
+	}
+
+	page, err = zc.ListJobsPage(&ListJobsOptions{PerPage: 3, CreatedAfter: time.Date(2010, 1, 1, 12, 0, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if query != "per_page=3" {
+		t.Fatal("Unexpected query", query)
+	}
+
+	if page.Page != 1 || len(page.Jobs) != 2 || page.Jobs[1].Job.Id != 2 {
+		t.Fatal("Expected jobs created after the bound", page.Jobs)
+	}
+
+	if page.HasMore {
+		t.Fatal("Expected no more pages past the bound")
+	}
+
+	// A short page is the last one
+	page, err = zc.ListJobsPage(nil)
+	if err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if query != "" || page.HasMore {
+		t.Fatal("Expected the last page", query, page.HasMore)
+	}
+}
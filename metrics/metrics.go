@@ -0,0 +1,299 @@
+// Package metrics instruments a Zencoder client and exposes request counts,
+// latencies and retries in the Prometheus text exposition format.
+//
+//	collector := metrics.New()
+//	collector.Instrument(zc)
+//	http.Handle("/metrics", collector)
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/brandscreen/zencoder"
+)
+
+// DefaultBuckets are the latency histogram buckets, in seconds
+var DefaultBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// ContentType is the media type of the text exposition format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+type endpointKey struct {
+	endpoint string
+	method   string
+}
+
+type requestKey struct {
+	endpointKey
+	code string
+}
+
+type rejectedKey struct {
+	endpointKey
+	reason string
+}
+
+type histogram struct {
+	counts []uint64 // per bucket, not cumulative
+	sum    float64
+	count  uint64
+}
+
+// Collector accumulates metrics for one or more Zencoder clients.  It is safe
+// for concurrent use.
+type Collector struct {
+	buckets []float64
+
+	mu        sync.Mutex
+	requests  map[requestKey]uint64
+	durations map[endpointKey]*histogram
+	retries   map[endpointKey]uint64
+	rejected  map[rejectedKey]uint64
+}
+
+// New returns a Collector using DefaultBuckets
+func New() *Collector {
+	return NewWithBuckets(DefaultBuckets)
+}
+
+// NewWithBuckets returns a Collector with custom latency buckets, in seconds
+func NewWithBuckets(buckets []float64) *Collector {
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+
+	return &Collector{
+		buckets:   sorted,
+		requests:  make(map[requestKey]uint64),
+		durations: make(map[endpointKey]*histogram),
+		retries:   make(map[endpointKey]uint64),
+		rejected:  make(map[rejectedKey]uint64),
+	}
+}
+
+// Instrument registers the collector's hook on a client
+func (c *Collector) Instrument(z *zencoder.Zencoder) {
+	z.Use(c.Hook())
+}
+
+// Hook returns a zencoder.Hook recording every request
+func (c *Collector) Hook() zencoder.Hook {
+	return zencoder.Hook{
+		BeforeRequest: func(ctx context.Context, call *zencoder.CallInfo) error {
+			if call.Attempt > 1 {
+				c.mu.Lock()
+				c.retries[keyOf(call)]++
+				c.mu.Unlock()
+			}
+			return nil
+		},
+		AfterResponse: func(ctx context.Context, call *zencoder.CallInfo) {
+			c.observe(call, strconv.Itoa(call.StatusCode))
+		},
+		OnError: func(ctx context.Context, call *zencoder.CallInfo, err error) {
+			// Calls never sent to Zencoder are not requests
+			if reason := rejection(ctx, err); reason != "" {
+				c.mu.Lock()
+				c.rejected[rejectedKey{keyOf(call), reason}]++
+				c.mu.Unlock()
+				return
+			}
+
+			// Calls failing with an unexpected status were observed in AfterResponse
+			if call.StatusCode == 0 {
+				c.observe(call, "error")
+			}
+		},
+	}
+}
+
+// rejection returns why a failed call was not sent to Zencoder, or "" if it was
+func rejection(ctx context.Context, err error) string {
+	var hookErr *zencoder.HookError
+	switch {
+	case errors.As(err, &hookErr):
+		return "hook"
+	case errors.Is(err, zencoder.ErrCircuitOpen):
+		return "circuit_open"
+	case ctx.Err() != nil:
+		return "cancelled"
+	}
+
+	return ""
+}
+
+func (c *Collector) observe(call *zencoder.CallInfo, code string) {
+	key := keyOf(call)
+	seconds := call.Latency.Seconds()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.requests[requestKey{key, code}]++
+
+	h, ok := c.durations[key]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(c.buckets))}
+		c.durations[key] = h
+	}
+
+	for i, bound := range c.buckets {
+		if seconds <= bound {
+			h.counts[i]++
+			break
+		}
+	}
+	h.sum += seconds
+	h.count++
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	c.WriteTo(w)
+}
+
+// WriteTo writes the metrics in the Prometheus text exposition format
+func (c *Collector) WriteTo(w io.Writer) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+
+	fmt.Fprintln(cw, "# HELP zencoder_requests_total Zencoder API requests by endpoint, method and status code.")
+	fmt.Fprintln(cw, "# TYPE zencoder_requests_total counter")
+	requestKeys := make([]requestKey, 0, len(c.requests))
+	for key := range c.requests {
+		requestKeys = append(requestKeys, key)
+	}
+	sort.Slice(requestKeys, func(i, j int) bool {
+		if requestKeys[i].endpointKey != requestKeys[j].endpointKey {
+			return less(requestKeys[i].endpointKey, requestKeys[j].endpointKey)
+		}
+		return requestKeys[i].code < requestKeys[j].code
+	})
+	for _, key := range requestKeys {
+		fmt.Fprintf(cw, "zencoder_requests_total{%s,code=%s} %d\n", labels(key.endpointKey), quote(key.code), c.requests[key])
+	}
+
+	fmt.Fprintln(cw, "# HELP zencoder_request_duration_seconds Zencoder API request latency by endpoint and method.")
+	fmt.Fprintln(cw, "# TYPE zencoder_request_duration_seconds histogram")
+	for _, key := range sortedKeys(c.durations) {
+		h := c.durations[key]
+		var cumulative uint64
+		for i, bound := range c.buckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(cw, "zencoder_request_duration_seconds_bucket{%s,le=%s} %d\n", labels(key), quote(formatFloat(bound)), cumulative)
+		}
+		fmt.Fprintf(cw, "zencoder_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels(key), h.count)
+		fmt.Fprintf(cw, "zencoder_request_duration_seconds_sum{%s} %s\n", labels(key), formatFloat(h.sum))
+		fmt.Fprintf(cw, "zencoder_request_duration_seconds_count{%s} %d\n", labels(key), h.count)
+	}
+
+	fmt.Fprintln(cw, "# HELP zencoder_retries_total Zencoder API request retries by endpoint and method.")
+	fmt.Fprintln(cw, "# TYPE zencoder_retries_total counter")
+	for _, key := range sortedKeys(c.retries) {
+		fmt.Fprintf(cw, "zencoder_retries_total{%s} %d\n", labels(key), c.retries[key])
+	}
+
+	fmt.Fprintln(cw, "# HELP zencoder_requests_rejected_total Zencoder API calls failed before being sent, by endpoint, method and reason.")
+	fmt.Fprintln(cw, "# TYPE zencoder_requests_rejected_total counter")
+	rejectedKeys := make([]rejectedKey, 0, len(c.rejected))
+	for key := range c.rejected {
+		rejectedKeys = append(rejectedKeys, key)
+	}
+	sort.Slice(rejectedKeys, func(i, j int) bool {
+		if rejectedKeys[i].endpointKey != rejectedKeys[j].endpointKey {
+			return less(rejectedKeys[i].endpointKey, rejectedKeys[j].endpointKey)
+		}
+		return rejectedKeys[i].reason < rejectedKeys[j].reason
+	})
+	for _, key := range rejectedKeys {
+		fmt.Fprintf(cw, "zencoder_requests_rejected_total{%s,reason=%s} %d\n", labels(key.endpointKey), quote(key.reason), c.rejected[key])
+	}
+
+	if cw.err == nil {
+		cw.err = cw.w.Flush()
+	}
+
+	return cw.n, cw.err
+}
+
+var idSegment = regexp.MustCompile(`^\d+(\.json)?$`)
+
+// Endpoint returns the path with its query removed and numeric ids replaced
+// by ":id", so that e.g. jobs/123/progress.json becomes jobs/:id/progress.json
+func Endpoint(path string) string {
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if m := idSegment.FindStringSubmatch(segment); m != nil {
+			segments[i] = ":id" + m[1]
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
+func keyOf(call *zencoder.CallInfo) endpointKey {
+	return endpointKey{endpoint: Endpoint(call.Path), method: call.Method}
+}
+
+func less(a, b endpointKey) bool {
+	if a.endpoint != b.endpoint {
+		return a.endpoint < b.endpoint
+	}
+	return a.method < b.method
+}
+
+func sortedKeys[V any](m map[endpointKey]V) []endpointKey {
+	keys := make([]endpointKey, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return less(keys[i], keys[j]) })
+	return keys
+}
+
+func labels(key endpointKey) string {
+	return "endpoint=" + quote(key.endpoint) + ",method=" + quote(key.method)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func quote(value string) string {
+	return `"` + labelEscaper.Replace(value) + `"`
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	if cw.err != nil {
+		return 0, cw.err
+	}
+
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	cw.err = err
+	return n, err
+}
@@ -0,0 +1,153 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brandscreen/zencoder"
+)
+
+func TestEndpoint(t *testing.T) {
+	tests := map[string]string{
+		"jobs":                        "jobs",
+		"jobs.json":                   "jobs.json",
+		"jobs/123.json":               "jobs/:id.json",
+		"jobs/123/progress.json":      "jobs/:id/progress.json",
+		"jobs/123/finish":             "jobs/:id/finish",
+		"reports/vod?from=2013-01-01": "reports/vod",
+	}
+
+	for in, expected := range tests {
+		if out := Endpoint(in); out != expected {
+			t.Fatal("Unexpected endpoint", in, out, expected)
+		}
+	}
+}
+
+func TestCollector(t *testing.T) {
+	calls := 0
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jobs/123/progress.json", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		fmt.Fprintln(w, `{"state": "processing"}`)
+	})
+	mux.HandleFunc("/jobs/456/progress.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"state": "finished"}`)
+	})
+
+	srv := httptest.NewServer(mux)
+
+	zc := zencoder.NewZencoder("abc", zencoder.WithBaseURL(srv.URL), zencoder.WithRetryPolicy(&zencoder.RetryPolicy{
+		MaxAttempts: 2,
+		MinBackoff:  time.Millisecond,
+	}))
+
+	collector := New()
+	collector.Instrument(zc)
+
+	if _, err := zc.GetJobProgress(123); err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if _, err := zc.GetJobProgress(456); err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if _, err := zc.GetJobDetails(123); err == nil {
+		t.Fatal("Expected error")
+	}
+
+	srv.Close()
+	if err := zc.CancelJob(123); err == nil {
+		t.Fatal("Expected error")
+	}
+
+	// Calls failing fast on an open circuit or aborted by a hook are not requests
+	breaking := zencoder.NewZencoder("abc", zencoder.WithBaseURL(srv.URL),
+		zencoder.WithBreaker(zencoder.NewBreaker(zencoder.BreakerSettings{FailureThreshold: 1, OpenTimeout: time.Minute})))
+	collector.Instrument(breaking)
+
+	for i := 0; i < 3; i++ {
+		if _, err := breaking.GetJobDetails(123); err == nil {
+			t.Fatal("Expected error")
+		}
+	}
+
+	zc.Use(zencoder.Hook{BeforeRequest: func(ctx context.Context, call *zencoder.CallInfo) error {
+		return errors.New("abort")
+	}})
+	if _, err := zc.GetJobDetails(123); err == nil {
+		t.Fatal("Expected error")
+	}
+
+	rec := httptest.NewRecorder()
+	collector.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	if ct := rec.Header().Get("Content-Type"); ct != ContentType {
+		t.Fatal("Expected Content-Type", ct)
+	}
+
+	body := rec.Body.String()
+	expected := []string{
+		"# TYPE zencoder_requests_total counter",
+		`zencoder_requests_total{endpoint="jobs/:id/progress.json",method="GET",code="200"} 2`,
+		`zencoder_requests_total{endpoint="jobs/:id/progress.json",method="GET",code="503"} 1`,
+		`zencoder_requests_total{endpoint="jobs/:id.json",method="GET",code="404"} 1`,
+		`zencoder_requests_total{endpoint="jobs/:id/cancel.json",method="PUT",code="error"} 1`,
+		`zencoder_requests_total{endpoint="jobs/:id.json",method="GET",code="error"} 1`,
+		"# TYPE zencoder_request_duration_seconds histogram",
+		`zencoder_request_duration_seconds_bucket{endpoint="jobs/:id/progress.json",method="GET",le="+Inf"} 3`,
+		`zencoder_request_duration_seconds_count{endpoint="jobs/:id/progress.json",method="GET"} 3`,
+		"# TYPE zencoder_retries_total counter",
+		`zencoder_retries_total{endpoint="jobs/:id/progress.json",method="GET"} 1`,
+		`zencoder_request_duration_seconds_count{endpoint="jobs/:id.json",method="GET"} 2`,
+		"# TYPE zencoder_requests_rejected_total counter",
+		`zencoder_requests_rejected_total{endpoint="jobs/:id.json",method="GET",reason="circuit_open"} 2`,
+		`zencoder_requests_rejected_total{endpoint="jobs/:id.json",method="GET",reason="hook"} 1`,
+	}
+
+	for _, line := range expected {
+		if !strings.Contains(body, line+"\n") {
+			t.Fatal("Expected line", line, "in", body)
+		}
+	}
+}
+
+func TestHistogramBuckets(t *testing.T) {
+	collector := NewWithBuckets([]float64{1, 0.1})
+
+	for _, latency := range []time.Duration{62500 * time.Microsecond, 500 * time.Millisecond, 4 * time.Second} {
+		collector.observe(&zencoder.CallInfo{Method: "GET", Path: "account", Latency: latency}, "200")
+	}
+
+	var sb strings.Builder
+	if _, err := collector.WriteTo(&sb); err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	expected := []string{
+		`zencoder_request_duration_seconds_bucket{endpoint="account",method="GET",le="0.1"} 1`,
+		`zencoder_request_duration_seconds_bucket{endpoint="account",method="GET",le="1"} 2`,
+		`zencoder_request_duration_seconds_bucket{endpoint="account",method="GET",le="+Inf"} 3`,
+		`zencoder_request_duration_seconds_sum{endpoint="account",method="GET"} 4.5625`,
+		`zencoder_request_duration_seconds_count{endpoint="account",method="GET"} 3`,
+	}
+
+	for _, line := range expected {
+		if !strings.Contains(sb.String(), line+"\n") {
+			t.Fatal("Expected line", line, "in", sb.String())
+		}
+	}
+}
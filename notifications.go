@@ -1,9 +1,27 @@
 package zencoder
 
+import (
+	"encoding/json"
+)
+
 type Notification struct {
 	Job     *Job               `json:"job,omitempty"`
 	Outputs []*OutputMediaFile `json:"outputs,omitempty"`
 	Input   *InputMediaFile    `json:"input,omitempty"`
+
+	// Fields not known to this package, preserved when re-marshalling
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+type notificationFields Notification
+
+func (n *Notification) UnmarshalJSON(b []byte) (err error) {
+	n.Extra, err = decodeExtra(b, (*notificationFields)(n))
+	return
+}
+
+func (n Notification) MarshalJSON() ([]byte, error) {
+	return encodeExtra(notificationFields(n), n.Extra)
 }
 
 func (n *Notification) Errors() (mediaFileErrors []*MediaFileError) {
@@ -0,0 +1,133 @@
+package zencoder
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Environment variables read by NewZencoderFromEnv
+const (
+	EnvAPIKey  = "ZENCODER_API_KEY"
+	EnvBaseURL = "ZENCODER_BASE_URL"
+)
+
+var ErrMissingAPIKey = errors.New("zencoder: " + EnvAPIKey + " is not set")
+
+// Option configures a Zencoder created by NewZencoder
+type Option func(*Zencoder)
+
+// WithBaseURL sets the API endpoint, e.g. for a proxy or a test server
+func WithBaseURL(baseUrl string) Option {
+	return func(z *Zencoder) {
+		z.BaseUrl = baseUrl
+	}
+}
+
+// WithHTTPClient sets the HTTP client used for requests.  It replaces any
+// timeout set by an earlier WithTimeout.
+func WithHTTPClient(client *http.Client) Option {
+	return func(z *Zencoder) {
+		z.Client = client
+	}
+}
+
+// WithTimeout sets the overall timeout of each HTTP request, 0 for none
+func WithTimeout(timeout time.Duration) Option {
+	return func(z *Zencoder) {
+		// Copy the client rather than changing one that may be shared
+		client := *z.Client
+		client.Timeout = timeout
+		z.Client = &client
+	}
+}
+
+// WithUserAgentSuffix appends a product token to the User-Agent header
+func WithUserAgentSuffix(suffix string) Option {
+	return func(z *Zencoder) {
+		z.Header.Set("User-Agent", strings.TrimSpace(z.Header.Get("User-Agent")+" "+suffix))
+	}
+}
+
+// WithRetryPolicy sets the policy for retrying transient failures
+func WithRetryPolicy(policy *RetryPolicy) Option {
+	return func(z *Zencoder) {
+		z.Retry = policy
+	}
+}
+
+// WithGovernor sets the rate and concurrency limits
+func WithGovernor(governor *Governor) Option {
+	return func(z *Zencoder) {
+		z.Governor = governor
+	}
+}
+
+// WithMaxResponseSize bounds decoded response bodies, in bytes; 0 for unlimited
+func WithMaxResponseSize(size int64) Option {
+	return func(z *Zencoder) {
+		z.MaxResponseSize = size
+	}
+}
+
+// WithStrictDecoding reports response fields unknown to this package, i.e.
+// drift in the Zencoder API, to fn
+func WithStrictDecoding(fn func(path string, fields []string)) Option {
+	return func(z *Zencoder) {
+		z.OnUnknownFields = fn
+	}
+}
+
+// WithBreaker sets the circuit breaker
+func WithBreaker(breaker *Breaker) Option {
+	return func(z *Zencoder) {
+		z.Breaker = breaker
+	}
+}
+
+// WithDryRun records mutating calls in dryRun instead of sending them
+func WithDryRun(dryRun *DryRun) Option {
+	return func(z *Zencoder) {
+		z.DryRun = dryRun
+	}
+}
+
+// WithCache caches the details of jobs and media files in terminal states
+func WithCache(cache *Cache) Option {
+	return func(z *Zencoder) {
+		z.Cache = cache
+	}
+}
+
+// WithCoalescer collapses identical in-flight GET requests into one
+func WithCoalescer(coalescer *Coalescer) Option {
+	return func(z *Zencoder) {
+		z.Coalescer = coalescer
+	}
+}
+
+// WithLogger sets the diagnostic logger
+func WithLogger(logger *slog.Logger) Option {
+	return func(z *Zencoder) {
+		z.Logger = logger
+	}
+}
+
+// NewZencoderFromEnv returns a client configured from ZENCODER_API_KEY and,
+// if set, ZENCODER_BASE_URL.  Options in opts take precedence over the environment.
+func NewZencoderFromEnv(opts ...Option) (*Zencoder, error) {
+	apiKey := os.Getenv(EnvAPIKey)
+	if apiKey == "" {
+		return nil, ErrMissingAPIKey
+	}
+
+	var envOpts []Option
+	if baseUrl := os.Getenv(EnvBaseURL); baseUrl != "" {
+		envOpts = append(envOpts, WithBaseURL(baseUrl))
+	}
+
+	return NewZencoder(apiKey, append(envOpts, opts...)...), nil
+}
@@ -0,0 +1,147 @@
+package zencoder
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewZencoderDefaults(t *testing.T) {
+	zc := NewZencoder("abc")
+
+	if zc.BaseUrl != DefaultBaseURL {
+		t.Fatal("Expected default base URL", zc.BaseUrl)
+	}
+
+	if zc.Client == http.DefaultClient {
+		t.Fatal("Expected a dedicated HTTP client")
+	}
+
+	if zc.Client.Timeout != DefaultTimeout {
+		t.Fatal("Expected default timeout", zc.Client.Timeout)
+	}
+
+	if zc.Retry != nil || zc.Governor != nil || zc.Logger != nil {
+		t.Fatal("Expected no retry policy, governor or logger by default")
+	}
+}
+
+func TestNewZencoderOptions(t *testing.T) {
+	client := &http.Client{}
+	policy := DefaultRetryPolicy()
+	governor := NewGovernor(Limit{})
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+
+	zc := NewZencoder("abc",
+		WithBaseURL("http://localhost:1234"),
+		WithHTTPClient(client),
+		WithTimeout(5*time.Second),
+		WithUserAgentSuffix("myservice/1.2"),
+		WithRetryPolicy(policy),
+		WithGovernor(governor),
+		WithLogger(logger),
+	)
+
+	if zc.BaseUrl != "http://localhost:1234" {
+		t.Fatal("Expected base URL to be set", zc.BaseUrl)
+	}
+
+	if zc.Client.Timeout != 5*time.Second {
+		t.Fatal("Expected timeout to be set", zc.Client.Timeout)
+	}
+
+	if client.Timeout != 0 {
+		t.Fatal("Expected the caller's client to be left untouched", client.Timeout)
+	}
+
+	if ua := zc.Header.Get("User-Agent"); ua != "gozencoder v1 myservice/1.2" {
+		t.Fatal("Expected User-Agent suffix", ua)
+	}
+
+	if zc.Retry != policy || zc.Governor != governor || zc.Logger != logger {
+		t.Fatal("Expected retry policy, governor and logger to be set")
+	}
+}
+
+func TestNewZencoderFromEnv(t *testing.T) {
+	t.Setenv(EnvAPIKey, "")
+	t.Setenv(EnvBaseURL, "")
+
+	_, err := NewZencoderFromEnv()
+	if !errors.Is(err, ErrMissingAPIKey) {
+		t.Fatal("Expected ErrMissingAPIKey", err)
+	}
+
+	t.Setenv(EnvAPIKey, "fromenv")
+
+	zc, err := NewZencoderFromEnv()
+	if err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if zc.Header.Get("Zencoder-Api-Key") != "fromenv" {
+		t.Fatal("Expected API key from environment", zc.Header)
+	}
+
+	if zc.BaseUrl != DefaultBaseURL {
+		t.Fatal("Expected default base URL", zc.BaseUrl)
+	}
+
+	t.Setenv(EnvBaseURL, "http://env.example.com")
+
+	zc, err = NewZencoderFromEnv()
+	if err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if zc.BaseUrl != "http://env.example.com" {
+		t.Fatal("Expected base URL from environment", zc.BaseUrl)
+	}
+
+	zc, err = NewZencoderFromEnv(WithBaseURL("http://option.example.com"))
+	if err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if zc.BaseUrl != "http://option.example.com" {
+		t.Fatal("Expected options to take precedence", zc.BaseUrl)
+	}
+}
+
+func TestLoggerRetries(t *testing.T) {
+	calls := 0
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jobs/123/progress.json", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		fmt.Fprintln(w, `{"state": "processing"}`)
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	var buf bytes.Buffer
+	zc := NewZencoder("abc",
+		WithBaseURL(srv.URL),
+		WithRetryPolicy(testRetryPolicy()),
+		WithLogger(slog.New(slog.NewTextHandler(&buf, nil))))
+
+	if _, err := zc.GetJobProgress(123); err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if !strings.Contains(buf.String(), "retrying request") {
+		t.Fatal("Expected the retry to be logged", buf.String())
+	}
+}
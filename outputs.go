@@ -1,14 +1,24 @@
 package zencoder
 
 import (
+	"context"
 	"fmt"
 )
 
 // Get Output Details
-func (z *Zencoder) GetOutputDetails(id int64) (*OutputMediaFile, error) {
+func (z *Zencoder) GetOutputDetails(id OutputID) (*OutputMediaFile, error) {
+	return z.GetOutputDetailsContext(context.Background(), id)
+}
+
+// Get Output Details with a Context
+func (z *Zencoder) GetOutputDetailsContext(ctx context.Context, id OutputID) (*OutputMediaFile, error) {
 	var details OutputMediaFile
 
-	if err := z.getBody(fmt.Sprintf("outputs/%d.json", id), &details); err != nil {
+	cacheable := func() (JobID, bool) {
+		return details.JobId, details.State.IsTerminal()
+	}
+
+	if err := z.getCached(ctx, fmt.Sprintf("outputs/%d.json", id), &details, cacheable); err != nil {
 		return nil, err
 	}
 
@@ -16,10 +26,15 @@ func (z *Zencoder) GetOutputDetails(id int64) (*OutputMediaFile, error) {
 }
 
 // Output Progress
-func (z *Zencoder) GetOutputProgress(id int64) (*FileProgress, error) {
+func (z *Zencoder) GetOutputProgress(id OutputID) (*FileProgress, error) {
+	return z.GetOutputProgressContext(context.Background(), id)
+}
+
+// Output Progress with a Context
+func (z *Zencoder) GetOutputProgressContext(ctx context.Context, id OutputID) (*FileProgress, error) {
 	var details FileProgress
 
-	if err := z.getBody(fmt.Sprintf("outputs/%d/progress.json", id), &details); err != nil {
+	if err := z.getBody(ctx, fmt.Sprintf("outputs/%d/progress.json", id), &details); err != nil {
 		return nil, err
 	}
 
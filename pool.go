@@ -0,0 +1,292 @@
+package zencoder
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+var ErrNoAccount = errors.New("zencoder: no account in the pool")
+
+// Rule routes jobs to an account.  A rule matches EncodingSettings when all
+// of its non-empty conditions do; a rule without conditions matches every job.
+type Rule struct {
+	Account           string // Name of the account jobs are created on
+	Grouping          string // Matches EncodingSettings.Grouping exactly
+	Region            string // Matches EncodingSettings.Region, ignoring case
+	PassThroughPrefix string // Matches an EncodingSettings.PassThrough starting with it
+}
+
+func (r *Rule) matches(settings *EncodingSettings) bool {
+	if r.Grouping != "" && r.Grouping != settings.Grouping {
+		return false
+	}
+
+	if r.Region != "" && !strings.EqualFold(r.Region, settings.Region) {
+		return false
+	}
+
+	if r.PassThroughPrefix != "" && !strings.HasPrefix(settings.PassThrough, r.PassThroughPrefix) {
+		return false
+	}
+
+	return true
+}
+
+// Pool holds clients for several named Zencoder accounts.
+//
+// CreateJob picks an account with the first matching Rule, falling back to
+// the default account, which is the first one added unless set otherwise.
+// The pool remembers which account created each job and sends later calls
+// for that job to the same account; a job it does not know is looked for in
+// every account in turn.  It remembers the 10000 most recently used jobs
+// unless set otherwise with SetMaxJobs.  A Pool is safe for concurrent use.
+type Pool struct {
+	mu         sync.RWMutex
+	accounts   map[string]*Zencoder
+	names      []string
+	rules      []Rule
+	defaultsTo string
+	jobs       map[JobID]*list.Element
+	lru        *list.List // Most recently used first
+	maxJobs    int
+}
+
+type trackedJob struct {
+	id      JobID
+	account string
+}
+
+// NewPool returns an empty Pool
+func NewPool() *Pool {
+	return &Pool{
+		accounts: make(map[string]*Zencoder),
+		jobs:     make(map[JobID]*list.Element),
+		lru:      list.New(),
+		maxJobs:  10000,
+	}
+}
+
+// Add adds or replaces a named account
+func (p *Pool) Add(name string, z *Zencoder) *Pool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.accounts[name]; !ok {
+		p.names = append(p.names, name)
+	}
+
+	if p.defaultsTo == "" {
+		p.defaultsTo = name
+	}
+
+	p.accounts[name] = z
+	return p
+}
+
+// Route appends a routing rule; rules are tried in the order they were added
+func (p *Pool) Route(rule Rule) *Pool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.rules = append(p.rules, rule)
+	return p
+}
+
+// SetDefault sets the account used when no rule matches
+func (p *Pool) SetDefault(name string) *Pool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.defaultsTo = name
+	return p
+}
+
+// SetMaxJobs sets how many jobs the pool remembers the account of, 0 for no
+// limit; the least recently used are forgotten first
+func (p *Pool) SetMaxJobs(n int) *Pool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.maxJobs = n
+	p.evict()
+	return p
+}
+
+// Account returns the client of a named account
+func (p *Pool) Account(name string) (*Zencoder, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	z, ok := p.accounts[name]
+	return z, ok
+}
+
+// Select returns the name and client of the account a job would be created on
+func (p *Pool) Select(settings *EncodingSettings) (string, *Zencoder, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	name := p.defaultsTo
+	for i := range p.rules {
+		if p.rules[i].matches(settings) {
+			name = p.rules[i].Account
+			break
+		}
+	}
+
+	if name == "" {
+		return "", nil, ErrNoAccount
+	}
+
+	z, ok := p.accounts[name]
+	if !ok {
+		return "", nil, fmt.Errorf("%w named %q", ErrNoAccount, name)
+	}
+
+	return name, z, nil
+}
+
+// JobAccount returns the name of the account known to have created a job
+func (p *Pool) JobAccount(id JobID) (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	e, ok := p.jobs[id]
+	if !ok {
+		return "", false
+	}
+
+	p.lru.MoveToFront(e)
+	return e.Value.(*trackedJob).account, true
+}
+
+// TrackJob records that a job belongs to a named account, e.g. after a restart
+func (p *Pool) TrackJob(id JobID, name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if e, ok := p.jobs[id]; ok {
+		e.Value.(*trackedJob).account = name
+		p.lru.MoveToFront(e)
+		return
+	}
+
+	p.jobs[id] = p.lru.PushFront(&trackedJob{id: id, account: name})
+	p.evict()
+}
+
+// ForgetJob forgets the account of a job, e.g. once it is no longer followed
+func (p *Pool) ForgetJob(id JobID) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if e, ok := p.jobs[id]; ok {
+		p.lru.Remove(e)
+		delete(p.jobs, id)
+	}
+}
+
+// evict forgets the least recently used jobs beyond maxJobs.  p.mu must be held.
+func (p *Pool) evict() {
+	for p.maxJobs > 0 && p.lru.Len() > p.maxJobs {
+		e := p.lru.Back()
+		p.lru.Remove(e)
+		delete(p.jobs, e.Value.(*trackedJob).id)
+	}
+}
+
+// Create a Job on the account selected by the routing rules
+func (p *Pool) CreateJob(settings *EncodingSettings) (*CreateJobResponse, error) {
+	return p.CreateJobContext(context.Background(), settings)
+}
+
+// Create a Job on the account selected by the routing rules, with a Context
+func (p *Pool) CreateJobContext(ctx context.Context, settings *EncodingSettings) (*CreateJobResponse, error) {
+	name, z, err := p.Select(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := z.CreateJobContext(ctx, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	p.TrackJob(result.Id, name)
+	return result, nil
+}
+
+// Get Job Details from the account that created the job
+func (p *Pool) GetJobDetails(id JobID) (*JobDetails, error) {
+	return p.GetJobDetailsContext(context.Background(), id)
+}
+
+// Get Job Details from the account that created the job, with a Context
+func (p *Pool) GetJobDetailsContext(ctx context.Context, id JobID) (*JobDetails, error) {
+	var details *JobDetails
+	err := p.forJob(id, func(z *Zencoder) (err error) {
+		details, err = z.GetJobDetailsContext(ctx, id)
+		return err
+	})
+
+	return details, err
+}
+
+// Get Job Progress from the account that created the job
+func (p *Pool) GetJobProgress(id JobID) (*JobProgress, error) {
+	return p.GetJobProgressContext(context.Background(), id)
+}
+
+// Get Job Progress from the account that created the job, with a Context
+func (p *Pool) GetJobProgressContext(ctx context.Context, id JobID) (*JobProgress, error) {
+	var progress *JobProgress
+	err := p.forJob(id, func(z *Zencoder) (err error) {
+		progress, err = z.GetJobProgressContext(ctx, id)
+		return err
+	})
+
+	return progress, err
+}
+
+// forJob calls fn with the client of the account that created a job.  If the
+// job is unknown, fn is tried on every account until one does not answer
+// ErrNotFound, and that account is remembered.
+func (p *Pool) forJob(id JobID, fn func(z *Zencoder) error) error {
+	if name, ok := p.JobAccount(id); ok {
+		z, ok := p.Account(name)
+		if !ok {
+			return fmt.Errorf("%w named %q", ErrNoAccount, name)
+		}
+
+		return fn(z)
+	}
+
+	p.mu.RLock()
+	names := append([]string(nil), p.names...)
+	p.mu.RUnlock()
+
+	err := ErrNoAccount
+	for _, name := range names {
+		z, ok := p.Account(name)
+		if !ok {
+			continue
+		}
+
+		err = fn(z)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+
+		if err == nil {
+			p.TrackJob(id, name)
+		}
+
+		return err
+	}
+
+	return err
+}
@@ -0,0 +1,146 @@
+package zencoder
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// accountServer serves a single account owning the jobs it creates
+func accountServer(firstId int64, created *[]string, name string) *httptest.Server {
+	jobs := map[string]bool{}
+	nextId := firstId
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jobs", func(w http.ResponseWriter, r *http.Request) {
+		*created = append(*created, name)
+		jobs[fmt.Sprint(nextId)] = true
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"id": %d}`, nextId)
+		nextId++
+	})
+	for id := firstId; id < firstId+3; id++ {
+		id := fmt.Sprint(id)
+		mux.HandleFunc("/jobs/"+id+".json", func(w http.ResponseWriter, r *http.Request) {
+			if !jobs[id] {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			fmt.Fprintf(w, `{"job": {"id": %s, "pass_through": %q}}`, id, name)
+		})
+		mux.HandleFunc("/jobs/"+id+"/progress.json", func(w http.ResponseWriter, r *http.Request) {
+			if !jobs[id] {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			fmt.Fprintln(w, `{"state": "processing"}`)
+		})
+	}
+
+	return httptest.NewServer(mux)
+}
+
+func TestPool(t *testing.T) {
+	var created []string
+
+	media := accountServer(100, &created, "media")
+	defer media.Close()
+	sports := accountServer(200, &created, "sports")
+	defer sports.Close()
+
+	pool := NewPool().
+		Add("media", NewZencoder("abc", WithBaseURL(media.URL))).
+		Add("sports", NewZencoder("def", WithBaseURL(sports.URL))).
+		Route(Rule{Account: "sports", Grouping: "football"}).
+		Route(Rule{Account: "sports", Region: "europe", PassThroughPrefix: "sports:"})
+
+	tests := []struct {
+		settings *EncodingSettings
+		account  string
+	}{
+		{&EncodingSettings{Grouping: "football"}, "sports"},
+		{&EncodingSettings{Region: "Europe", PassThrough: "sports:42"}, "sports"},
+		{&EncodingSettings{Region: "US", PassThrough: "sports:42"}, "media"},
+		{&EncodingSettings{}, "media"},
+	}
+
+	for _, test := range tests {
+		name, _, err := pool.Select(test.settings)
+		if err != nil || name != test.account {
+			t.Fatal("Unexpected account", test.settings, name, err)
+		}
+	}
+
+	resp, err := pool.CreateJob(&EncodingSettings{Grouping: "football"})
+	if err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if resp.Id != 200 || len(created) != 1 || created[0] != "sports" {
+		t.Fatal("Expected the job to be created on sports", resp.Id, created)
+	}
+
+	if name, ok := pool.JobAccount(200); !ok || name != "sports" {
+		t.Fatal("Expected the job to be tracked", name)
+	}
+
+	if _, err := pool.GetJobProgress(200); err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	// A job created elsewhere is found by trying every account
+	pool = NewPool().
+		Add("media", NewZencoder("abc", WithBaseURL(media.URL))).
+		Add("sports", NewZencoder("def", WithBaseURL(sports.URL)))
+
+	details, err := pool.GetJobDetails(200)
+	if err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if *details.Job.PassThrough != "sports" {
+		t.Fatal("Expected the job from sports", *details.Job.PassThrough)
+	}
+
+	if name, _ := pool.JobAccount(200); name != "sports" {
+		t.Fatal("Expected the job to be tracked once found", name)
+	}
+
+	// Forgotten jobs are found again by trying every account
+	pool.ForgetJob(200)
+	if _, ok := pool.JobAccount(200); ok {
+		t.Fatal("Expected the job to be forgotten")
+	}
+
+	if _, err := pool.GetJobProgress(200); err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	// Only the most recently used jobs are remembered
+	pool.SetMaxJobs(2)
+	pool.TrackJob(1, "media")
+	pool.JobAccount(200)
+	pool.TrackJob(2, "media")
+	if _, ok := pool.JobAccount(1); ok {
+		t.Fatal("Expected the least recently used job to be forgotten")
+	}
+
+	if name, _ := pool.JobAccount(200); name != "sports" {
+		t.Fatal("Expected the recently used job to be remembered", name)
+	}
+
+	if _, err := pool.GetJobDetails(999); !errors.Is(err, ErrNotFound) {
+		t.Fatal("Expected ErrNotFound", err)
+	}
+
+	if _, err := NewPool().CreateJob(&EncodingSettings{}); !errors.Is(err, ErrNoAccount) {
+		t.Fatal("Expected ErrNoAccount", err)
+	}
+
+	pool.Route(Rule{Account: "missing"})
+	if _, _, err := pool.Select(&EncodingSettings{}); !errors.Is(err, ErrNoAccount) {
+		t.Fatal("Expected ErrNoAccount for an unknown account", err)
+	}
+}
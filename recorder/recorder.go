@@ -0,0 +1,268 @@
+// Package recorder provides an http.RoundTripper that records Zencoder API
+// interactions into cassette files and replays them offline, so integration
+// code can be tested deterministically.
+//
+//	rec, err := recorder.New("testdata/create_job.json", recorder.ModeReplay)
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	defer rec.Stop()
+//
+//	zc := zencoder.NewZencoder("key", zencoder.WithHTTPClient(rec.Client()))
+//
+// API keys, credentials and encryption secrets are redacted before anything is
+// written to a cassette.
+package recorder
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/brandscreen/zencoder"
+)
+
+// Mode selects whether a Recorder talks to the real API
+type Mode int
+
+const (
+	ModeReplay Mode = iota // Serve responses from the cassette, never touching the network
+	ModeRecord             // Forward requests to the real API and record them
+)
+
+var ErrNoInteraction = errors.New("recorder: no recorded interaction matches the request")
+
+// Request is a recorded request
+type Request struct {
+	Method string      `json:"method"`
+	Path   string      `json:"path"`
+	Query  string      `json:"query,omitempty"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// Response is a recorded response
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Interaction is a request and the response it received
+type Interaction struct {
+	Request  *Request  `json:"request"`
+	Response *Response `json:"response"`
+}
+
+// Cassette is the content of a cassette file
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Recorder is an http.RoundTripper recording to, or replaying from, a cassette file.
+// It is safe for concurrent use.
+type Recorder struct {
+	// Transport performs the real requests in ModeRecord; nil means http.DefaultTransport
+	Transport http.RoundTripper
+
+	mode Mode
+	path string
+
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+}
+
+// New returns a Recorder for the cassette at path.  In ModeReplay the cassette
+// must exist; in ModeRecord it is written by Stop.
+func New(path string, mode Mode) (*Recorder, error) {
+	r := &Recorder{
+		mode:     mode,
+		path:     path,
+		cassette: &Cassette{},
+	}
+
+	if mode == ModeReplay {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(b, r.cassette); err != nil {
+			return nil, fmt.Errorf("recorder: invalid cassette %s: %v", path, err)
+		}
+
+		r.used = make([]bool, len(r.cassette.Interactions))
+	}
+
+	return r, nil
+}
+
+// Client returns an http.Client using the recorder as its transport
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// Interactions returns the interactions recorded or loaded so far
+func (r *Recorder) Interactions() []*Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]*Interaction(nil), r.cassette.Interactions...)
+}
+
+// Stop writes the cassette file when recording.  It does nothing when replaying.
+func (r *Recorder) Stop() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(r.path, append(b, '\n'), 0644)
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	// The caller's request must not be modified, so its body is read from and
+	// restored on a clone, which is what gets sent
+	sent := req.Clone(req.Context())
+	recorded, err := newRequest(sent)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.mode == ModeRecord {
+		return r.record(sent, recorded)
+	}
+
+	return r.replay(req, recorded)
+}
+
+func (r *Recorder) record(req *http.Request, recorded *Request) (*http.Response, error) {
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, &Interaction{
+		Request: recorded,
+		Response: &Response{
+			StatusCode: resp.StatusCode,
+			Header:     zencoder.RedactHeader(resp.Header),
+			Body:       redactBody(body),
+		},
+	})
+	r.mu.Unlock()
+
+	// The caller gets the real, unredacted response
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+func (r *Recorder) replay(req *http.Request, recorded *Request) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Use interactions in order, so a polled endpoint can return successive
+	// responses; once all matches are used, keep replaying the last one
+	match := -1
+	for i, interaction := range r.cassette.Interactions {
+		if !matches(interaction.Request, recorded) {
+			continue
+		}
+
+		match = i
+		if !r.used[i] {
+			break
+		}
+	}
+
+	if match < 0 {
+		return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, recorded.Method, recorded.Path)
+	}
+	r.used[match] = true
+
+	response := r.cassette.Interactions[match].Response
+	header := response.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", response.StatusCode, http.StatusText(response.StatusCode)),
+		StatusCode:    response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader([]byte(response.Body))),
+		ContentLength: int64(len(response.Body)),
+		Request:       req,
+	}, nil
+}
+
+// newRequest captures a request in its redacted, canonical form.  The body of
+// req, which must be a clone of the caller's request, is restored so that it
+// can still be sent.
+func newRequest(req *http.Request) (*Request, error) {
+	var body []byte
+	if req.Body != nil {
+		b, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		body = b
+		req.Body = io.NopCloser(bytes.NewReader(b))
+	}
+
+	return &Request{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Query:  req.URL.Query().Encode(),
+		Header: zencoder.RedactHeader(req.Header),
+		Body:   redactBody(body),
+	}, nil
+}
+
+// matches compares requests on method, path, query and body
+func matches(recorded, req *Request) bool {
+	return recorded.Method == req.Method &&
+		recorded.Path == req.Path &&
+		recorded.Query == req.Query &&
+		recorded.Body == req.Body
+}
+
+// redactBody redacts a JSON body, normalising it so that equivalent bodies compare equal
+func redactBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	return string(zencoder.RedactJSON(body))
+}
@@ -1,6 +1,7 @@
 package zencoder
 
 import (
+	"context"
 	"net/url"
 	"time"
 )
@@ -124,9 +125,14 @@ func GetReportQuery(path string, settings *ReportSettings) string {
 
 // Get VOD Usage
 func (z *Zencoder) GetVodUsage(settings *ReportSettings) (*VodUsage, error) {
+	return z.GetVodUsageContext(context.Background(), settings)
+}
+
+// Get VOD Usage with a Context
+func (z *Zencoder) GetVodUsageContext(ctx context.Context, settings *ReportSettings) (*VodUsage, error) {
 	var details VodUsage
 
-	if err := z.getBody(GetReportQuery("reports/vod", settings), &details); err != nil {
+	if err := z.getBody(ctx, GetReportQuery("reports/vod", settings), &details); err != nil {
 		return nil, err
 	}
 
@@ -135,9 +141,14 @@ func (z *Zencoder) GetVodUsage(settings *ReportSettings) (*VodUsage, error) {
 
 // Get Live Usage
 func (z *Zencoder) GetLiveUsage(settings *ReportSettings) (*LiveUsage, error) {
+	return z.GetLiveUsageContext(context.Background(), settings)
+}
+
+// Get Live Usage with a Context
+func (z *Zencoder) GetLiveUsageContext(ctx context.Context, settings *ReportSettings) (*LiveUsage, error) {
 	var details LiveUsage
 
-	if err := z.getBody(GetReportQuery("reports/live", settings), &details); err != nil {
+	if err := z.getBody(ctx, GetReportQuery("reports/live", settings), &details); err != nil {
 		return nil, err
 	}
 
@@ -145,9 +156,14 @@ func (z *Zencoder) GetLiveUsage(settings *ReportSettings) (*LiveUsage, error) {
 }
 
 func (z *Zencoder) GetUsage(settings *ReportSettings) (*CombinedUsage, error) {
+	return z.GetUsageContext(context.Background(), settings)
+}
+
+// Get Combined Usage with a Context
+func (z *Zencoder) GetUsageContext(ctx context.Context, settings *ReportSettings) (*CombinedUsage, error) {
 	var details CombinedUsage
 
-	if err := z.getBody(GetReportQuery("reports/all", settings), &details); err != nil {
+	if err := z.getBody(ctx, GetReportQuery("reports/all", settings), &details); err != nil {
 		return nil, err
 	}
 
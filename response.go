@@ -0,0 +1,114 @@
+package zencoder
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+)
+
+const (
+	// DefaultMaxResponseSize bounds the size of a decoded response body
+	DefaultMaxResponseSize = 32 << 20
+
+	// maxErrorBodySize bounds the body kept in an APIError
+	maxErrorBodySize = 64 << 10
+
+	// maxDrainSize bounds how much of an unread body is discarded so that the
+	// connection can be reused; larger bodies are closed without draining
+	maxDrainSize = 64 << 10
+)
+
+var ErrResponseTooLarge = errors.New("zencoder: response body exceeds the maximum size")
+
+// limitReader reads at most n bytes from r, failing with ErrResponseTooLarge
+// if r holds more
+type limitReader struct {
+	r io.Reader
+	n int64
+}
+
+func (l *limitReader) Read(p []byte) (int, error) {
+	if l.n < 0 {
+		return l.r.Read(p)
+	}
+
+	if int64(len(p)) > l.n+1 {
+		p = p[:l.n+1]
+	}
+
+	n, err := l.r.Read(p)
+	if int64(n) > l.n {
+		l.n = 0
+		return 0, ErrResponseTooLarge
+	}
+
+	l.n -= int64(n)
+	return n, err
+}
+
+// newLimitReader bounds r to max bytes, or leaves it unbounded if max <= 0
+func newLimitReader(r io.Reader, max int64) io.Reader {
+	if max <= 0 {
+		max = -1
+	}
+
+	return &limitReader{r: r, n: max}
+}
+
+// drainAndClose discards what is left of a response body and closes it,
+// letting the HTTP client reuse the connection
+func drainAndClose(body io.ReadCloser) error {
+	io.CopyN(io.Discard, body, maxDrainSize)
+	return body.Close()
+}
+
+// decodeBody streams a JSON body into result, reading at most max bytes
+func decodeBody(body io.ReadCloser, result interface{}, max int64) error {
+	defer drainAndClose(body)
+
+	err := json.NewDecoder(newLimitReader(body, max)).Decode(result)
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+
+	return err
+}
+
+// decode decodes a response body into result, reporting unknown fields to
+// OnUnknownFields when strict decoding is enabled
+func (z *Zencoder) decode(path string, body io.ReadCloser, result interface{}) error {
+	if z.OnUnknownFields == nil {
+		return decodeBody(body, result, z.MaxResponseSize)
+	}
+
+	defer drainAndClose(body)
+
+	b, err := io.ReadAll(newLimitReader(body, z.MaxResponseSize))
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(b, result); err != nil {
+		return err
+	}
+
+	var raw interface{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	if fields := unknownFields(raw, reflect.TypeOf(result)); len(fields) > 0 {
+		z.OnUnknownFields(path, fields)
+	}
+
+	return nil
+}
+
+// readErrorBody reads at most maxErrorBodySize bytes of an error response
+func readErrorBody(body io.ReadCloser) []byte {
+	defer drainAndClose(body)
+
+	b, _ := io.ReadAll(io.LimitReader(body, maxErrorBodySize))
+	return b
+}
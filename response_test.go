@@ -0,0 +1,244 @@
+package zencoder
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// trackingTransport counts response bodies that were opened but never closed
+type trackingTransport struct {
+	open int32
+}
+
+type trackingBody struct {
+	io.ReadCloser
+	t      *trackingTransport
+	closed int32
+}
+
+func (b *trackingBody) Close() error {
+	if atomic.CompareAndSwapInt32(&b.closed, 0, 1) {
+		atomic.AddInt32(&b.t.open, -1)
+	}
+	return b.ReadCloser.Close()
+}
+
+func (t *trackingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+
+	atomic.AddInt32(&t.open, 1)
+	resp.Body = &trackingBody{ReadCloser: resp.Body, t: t}
+	return resp, nil
+}
+
+func TestResponseBodiesClosed(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jobs/123/cancel.json", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("/jobs/123.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"job": {"id": 123}}`)
+	})
+	mux.HandleFunc("/jobs/456.json", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprintln(w, `{"errors": ["not found"]}`)
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	transport := &trackingTransport{}
+	zc := NewZencoder("abc", WithBaseURL(srv.URL), WithHTTPClient(&http.Client{Transport: transport}))
+
+	if err := zc.CancelJob(123); err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if _, err := zc.GetJobDetails(123); err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if _, err := zc.GetJobDetails(456); err == nil {
+		t.Fatal("Expected error")
+	}
+
+	if open := atomic.LoadInt32(&transport.open); open != 0 {
+		t.Fatal("Expected every response body to be closed", open)
+	}
+}
+
+func TestMaxResponseSize(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jobs.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"job": {"id": 1, "state": "finished"}}]`)
+	})
+	mux.HandleFunc("/jobs/123.json", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, strings.Repeat("x", 2*maxErrorBodySize))
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	zc := NewZencoder("abc", WithBaseURL(srv.URL), WithMaxResponseSize(16))
+
+	_, err := zc.ListJobs()
+	if !errors.Is(err, ErrResponseTooLarge) {
+		t.Fatal("Expected ErrResponseTooLarge", err)
+	}
+
+	zc.MaxResponseSize = 0
+	jobs, err := zc.ListJobs()
+	if err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if len(jobs) != 1 {
+		t.Fatal("Expected one job", len(jobs))
+	}
+
+	_, err = zc.GetJobDetails(123)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatal("Expected an APIError", err)
+	}
+
+	if len(apiErr.Body) != maxErrorBodySize {
+		t.Fatal("Expected the error body to be bounded", len(apiErr.Body))
+	}
+}
+
+func TestLimitReader(t *testing.T) {
+	b, err := io.ReadAll(newLimitReader(strings.NewReader("12345"), 5))
+	if err != nil || string(b) != "12345" {
+		t.Fatal("Expected a body at the limit to be read", string(b), err)
+	}
+
+	_, err = io.ReadAll(newLimitReader(strings.NewReader("123456"), 5))
+	if !errors.Is(err, ErrResponseTooLarge) {
+		t.Fatal("Expected ErrResponseTooLarge", err)
+	}
+
+	b, err = io.ReadAll(newLimitReader(strings.NewReader("123456"), 0))
+	if err != nil || string(b) != "123456" {
+		t.Fatal("Expected no limit", string(b), err)
+	}
+}
+
+func largeJobList(n int) []byte {
+	jobs := make([]*JobDetails, n)
+	for i := range jobs {
+		label := fmt.Sprintf("output-%d", i)
+		jobs[i] = &JobDetails{Job: &Job{
+			Id:             JobID(i),
+			State:          "finished",
+			InputMediaFile: &MediaFile{Id: int64(i), Url: "s3://bucket/input.mov", State: "finished"},
+			OutputMediaFiles: []*MediaFile{
+				{Id: int64(i), Label: &label, Url: "s3://bucket/output.mp4", State: "finished"},
+			},
+			CreatedAt: "2010-01-01T00:00:00Z",
+		}}
+	}
+
+	b, _ := json.Marshal(jobs)
+	return b
+}
+
+// BenchmarkDecodeReadAll is the previous behaviour of buffering the whole
+// body before decoding it, on the same body as BenchmarkDecodeStreaming.
+// Both take about as long and allocate about as much: streaming does not
+// speed up decoding bodies of a normal size.
+func BenchmarkDecodeReadAll(b *testing.B) {
+	body := largeJobList(1000)
+	b.SetBytes(int64(len(body)))
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		var result []*JobDetails
+		data, err := io.ReadAll(io.NopCloser(bytes.NewReader(body)))
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err := json.Unmarshal(data, &result); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeStreaming(b *testing.B) {
+	body := largeJobList(1000)
+	b.SetBytes(int64(len(body)))
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		var result []*JobDetails
+		if err := decodeBody(io.NopCloser(bytes.NewReader(body)), &result, DefaultMaxResponseSize); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// oversizedBody is a response far larger than the limits used below
+var oversizedBody = append(largeJobList(5000), bytes.Repeat([]byte(" "), 8<<20)...)
+
+// BenchmarkOversizedReadAll is the previous behaviour of buffering the whole
+// body before decoding, whatever its size.  Compared with
+// BenchmarkOversizedBounded, it measures what the size limit saves by
+// aborting early, not the cost of streaming.
+func BenchmarkOversizedReadAll(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		var result []*JobDetails
+		data, err := io.ReadAll(bytes.NewReader(oversizedBody))
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err := json.Unmarshal(data, &result); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkOversizedBounded(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		var result []*JobDetails
+		err := decodeBody(io.NopCloser(bytes.NewReader(oversizedBody)), &result, 1<<20)
+		if !errors.Is(err, ErrResponseTooLarge) {
+			b.Fatal("Expected ErrResponseTooLarge", err)
+		}
+	}
+}
+
+// BenchmarkErrorBodyReadAll is the previous behaviour of reading a whole
+// error body into the error
+func BenchmarkErrorBodyReadAll(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := io.ReadAll(bytes.NewReader(oversizedBody)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkErrorBodyBounded(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		readErrorBody(io.NopCloser(bytes.NewReader(oversizedBody)))
+	}
+}
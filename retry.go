@@ -0,0 +1,138 @@
+package zencoder
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how transient failures are retried.
+//
+// Requests are retried on connection errors, 429 Too Many Requests and
+// 500/502/503/504 responses.  Only safe (GET) requests are retried, plus
+// CreateJob when RetryCreateJob is set; CreateJob is not idempotent, so a
+// retry after an ambiguous failure may create a duplicate job.
+type RetryPolicy struct {
+	MaxAttempts    int           // Total number of attempts, including the first one
+	MinBackoff     time.Duration // Backoff before the first retry, doubled on each subsequent retry
+	MaxBackoff     time.Duration // Upper bound on the backoff, including one asked for by Retry-After
+	Jitter         float64       // Fraction (0-1) of each backoff that is randomised
+	RetryCreateJob bool          // Also retry CreateJob
+}
+
+// DefaultRetryPolicy returns a policy making up to three attempts with jittered exponential backoff
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 3,
+		MinBackoff:  500 * time.Millisecond,
+		MaxBackoff:  10 * time.Second,
+		Jitter:      0.2,
+	}
+}
+
+func (p *RetryPolicy) allows(method, path string) bool {
+	switch method {
+	case "GET":
+		return true
+	case "POST":
+		return path == "jobs" && p.RetryCreateJob
+	}
+
+	return false
+}
+
+type noRetriesKey struct{}
+
+// withoutRetries returns a context whose requests are never retried by call()
+func withoutRetries(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noRetriesKey{}, true)
+}
+
+func (p *RetryPolicy) shouldRetry(ctx context.Context, method, path string, attempt int, resp *http.Response, err error) bool {
+	if p == nil || attempt >= p.MaxAttempts || !p.allows(method, path) || ctx.Value(noRetriesKey{}) != nil {
+		return false
+	}
+
+	if err != nil {
+		// Cancellation is the caller's decision, an open circuit must fail fast
+		// and a hook aborting the call would abort it again
+		var hookErr *HookError
+		return ctx.Err() == nil && !errors.Is(err, ErrCircuitOpen) && !errors.As(err, &hookErr)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
+
+// backoff returns how long to wait after the given attempt, honouring
+// Retry-After if present, up to MaxBackoff
+func (p *RetryPolicy) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if wait, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+			if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+				wait = p.MaxBackoff
+			}
+			return wait
+		}
+	}
+
+	wait := p.MinBackoff
+	for i := 1; i < attempt && (p.MaxBackoff <= 0 || wait < p.MaxBackoff); i++ {
+		wait *= 2
+	}
+
+	if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+		wait = p.MaxBackoff
+	}
+
+	if p.Jitter > 0 {
+		wait -= time.Duration(rand.Float64() * p.Jitter * float64(wait))
+	}
+
+	return wait
+}
+
+// retryAfter parses a Retry-After header given either in seconds or as an HTTP date
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+
+	return 0, false
+}
+
+// sleep waits for d, returning early with the context error if ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,244 @@
+package zencoder
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func testRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 3,
+		MinBackoff:  time.Millisecond,
+		MaxBackoff:  5 * time.Millisecond,
+	}
+}
+
+func TestRetryGet(t *testing.T) {
+	calls := 0
+	failures := 2
+	failStatus := http.StatusServiceUnavailable
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jobs/123.json", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls <= failures {
+			w.WriteHeader(failStatus)
+			return
+		}
+
+		fmt.Fprintln(w, `{"job": {"id": 123}}`)
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	zc := NewZencoder("abc")
+	zc.BaseUrl = srv.URL
+	zc.Retry = testRetryPolicy()
+
+	details, err := zc.GetJobDetails(123)
+	if err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if details.Job.Id != 123 {
+		t.Fatal("Expected Id=123", details.Job.Id)
+	}
+
+	if calls != 3 {
+		t.Fatal("Expected 3 calls", calls)
+	}
+
+	// Exhausting the attempts returns the last error
+	calls = 0
+	failures = 5
+	_, err = zc.GetJobDetails(123)
+	if err == nil {
+		t.Fatal("Expected error")
+	}
+
+	if calls != 3 {
+		t.Fatal("Expected 3 calls", calls)
+	}
+
+	// Client errors are not retried
+	calls = 0
+	failStatus = http.StatusNotFound
+	_, err = zc.GetJobDetails(123)
+	if err == nil {
+		t.Fatal("Expected error")
+	}
+
+	if calls != 1 {
+		t.Fatal("Expected 1 call", calls)
+	}
+}
+
+func TestRetryConnectionReset(t *testing.T) {
+	calls := 0
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jobs/123/progress.json", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Fatal(err)
+			}
+			conn.Close()
+			return
+		}
+
+		fmt.Fprintln(w, `{"state": "processing"}`)
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	zc := NewZencoder("abc")
+	zc.BaseUrl = srv.URL
+	zc.Retry = testRetryPolicy()
+
+	progress, err := zc.GetJobProgress(123)
+	if err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if progress.State != "processing" {
+		t.Fatal("Expected processing", progress.State)
+	}
+
+	if calls != 2 {
+		t.Fatal("Expected 2 calls", calls)
+	}
+}
+
+func TestRetryCreateJob(t *testing.T) {
+	calls := 0
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jobs", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintln(w, `{"id": 1234}`)
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	zc := NewZencoder("abc")
+	zc.BaseUrl = srv.URL
+	zc.Retry = testRetryPolicy()
+
+	_, err := zc.CreateJob(&EncodingSettings{})
+	if err == nil {
+		t.Fatal("Expected error")
+	}
+
+	if calls != 1 {
+		t.Fatal("Expected CreateJob not to be retried", calls)
+	}
+
+	calls = 0
+	zc.Retry.RetryCreateJob = true
+
+	resp, err := zc.CreateJob(&EncodingSettings{})
+	if err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if resp.Id != 1234 {
+		t.Fatal("Expected Id=1234", resp.Id)
+	}
+
+	if calls != 2 {
+		t.Fatal("Expected 2 calls", calls)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	var last time.Time
+	var waited time.Duration
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jobs/123/progress.json", func(w http.ResponseWriter, r *http.Request) {
+		if last.IsZero() {
+			last = time.Now()
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		waited = time.Since(last)
+		fmt.Fprintln(w, `{"state": "processing"}`)
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	zc := NewZencoder("abc")
+	zc.BaseUrl = srv.URL
+	zc.Retry = testRetryPolicy()
+	zc.Retry.MaxBackoff = 5 * time.Second
+
+	_, err := zc.GetJobProgress(123)
+	if err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if waited < 900*time.Millisecond {
+		t.Fatal("Expected Retry-After to be honoured", waited)
+	}
+
+	// Retry-After is capped at MaxBackoff
+	resp := &http.Response{Header: http.Header{"Retry-After": {"3600"}}}
+	if wait := zc.Retry.backoff(1, resp); wait != 5*time.Second {
+		t.Fatal("Expected Retry-After to be capped", wait)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	policy := &RetryPolicy{
+		MinBackoff: 100 * time.Millisecond,
+		MaxBackoff: time.Second,
+	}
+
+	expected := []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		800 * time.Millisecond,
+		time.Second,
+		time.Second,
+	}
+
+	for i, wait := range expected {
+		if got := policy.backoff(i+1, nil); got != wait {
+			t.Fatal("Unexpected backoff", i+1, got, wait)
+		}
+	}
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		got := policy.backoff(1, nil)
+		if got < 50*time.Millisecond || got > 100*time.Millisecond {
+			t.Fatal("Expected jitter within bounds", got)
+		}
+	}
+
+	if wait, ok := retryAfter("Wed, 21 Oct 2015 07:28:00 GMT"); !ok || wait != 0 {
+		t.Fatal("Expected past HTTP date to parse as zero wait", wait, ok)
+	}
+
+	if _, ok := retryAfter("soon"); ok {
+		t.Fatal("Expected invalid Retry-After to be ignored")
+	}
+}
@@ -249,8 +249,8 @@ type OutputSettings struct {
 	CopyAudio bool `json:"copy_audio,omitempty"` // Copy the audio track of the input file
 
 	// Fields in Notifications
-	Id    int64  `json:"id,omitempty"`
-	State string `json:"state,omitempty"`
+	Id    int64     `json:"id,omitempty"`
+	State FileState `json:"state,omitempty"`
 }
 
 type EncodingSettings struct {
@@ -0,0 +1,128 @@
+package zencoder
+
+// JobState is the state of a Job.  States unknown to this package, e.g.
+// introduced by a later version of the API, are kept as they are.
+type JobState string
+
+const (
+	JobStatePending    JobState = "pending"
+	JobStateWaiting    JobState = "waiting"
+	JobStateProcessing JobState = "processing"
+	JobStateFinished   JobState = "finished"
+	JobStateFailed     JobState = "failed"
+	JobStateCancelled  JobState = "cancelled"
+)
+
+// FileState is the state of an input or output media file.  States unknown
+// to this package are kept as they are.
+type FileState string
+
+const (
+	FileStatePending    FileState = "pending"
+	FileStateWaiting    FileState = "waiting"
+	FileStateQueued     FileState = "queued"
+	FileStateAssigning  FileState = "assigning"
+	FileStateProcessing FileState = "processing"
+	FileStateFinished   FileState = "finished"
+	FileStateFailed     FileState = "failed"
+	FileStateCancelled  FileState = "cancelled"
+	FileStateNoInput    FileState = "no_input"
+	FileStateSkipped    FileState = "skipped"
+)
+
+// Position of each known state in the lifecycle; terminal states share the last one
+var (
+	jobStateRanks = map[JobState]int{
+		JobStatePending:    0,
+		JobStateWaiting:    1,
+		JobStateProcessing: 2,
+		JobStateFinished:   3,
+		JobStateFailed:     3,
+		JobStateCancelled:  3,
+	}
+
+	fileStateRanks = map[FileState]int{
+		FileStatePending:    0,
+		FileStateWaiting:    1,
+		FileStateQueued:     2,
+		FileStateAssigning:  3,
+		FileStateProcessing: 4,
+		FileStateFinished:   5,
+		FileStateFailed:     5,
+		FileStateCancelled:  5,
+		FileStateNoInput:    5,
+		FileStateSkipped:    5,
+	}
+)
+
+// IsKnown reports whether the state is one documented by Zencoder
+func (s JobState) IsKnown() bool {
+	_, ok := jobStateRanks[s]
+	return ok
+}
+
+// IsTerminal reports whether the job is finished, failed or cancelled
+func (s JobState) IsTerminal() bool {
+	return s == JobStateFinished || s == JobStateFailed || s == JobStateCancelled
+}
+
+// IsSuccess reports whether the job finished
+func (s JobState) IsSuccess() bool {
+	return s == JobStateFinished
+}
+
+// CanTransitionTo reports whether a job may move from s to next.  Jobs only
+// move forward, except that a failed or cancelled job may be resubmitted.
+// Transitions from or to unknown states are allowed.
+func (s JobState) CanTransitionTo(next JobState) bool {
+	from, ok := jobStateRanks[s]
+	to, nextOk := jobStateRanks[next]
+	if !ok || !nextOk || s == next {
+		return true
+	}
+
+	if s.IsTerminal() {
+		return (s == JobStateFailed || s == JobStateCancelled) && (next == JobStatePending || next == JobStateWaiting)
+	}
+
+	return to > from
+}
+
+// IsKnown reports whether the state is one documented by Zencoder
+func (s FileState) IsKnown() bool {
+	_, ok := fileStateRanks[s]
+	return ok
+}
+
+// IsTerminal reports whether the file is finished, failed, cancelled, skipped
+// or has no input
+func (s FileState) IsTerminal() bool {
+	switch s {
+	case FileStateFinished, FileStateFailed, FileStateCancelled, FileStateNoInput, FileStateSkipped:
+		return true
+	}
+
+	return false
+}
+
+// IsSuccess reports whether the file finished
+func (s FileState) IsSuccess() bool {
+	return s == FileStateFinished
+}
+
+// CanTransitionTo reports whether a file may move from s to next.  Files only
+// move forward, except that a failed or cancelled file may be resubmitted.
+// Transitions from or to unknown states are allowed.
+func (s FileState) CanTransitionTo(next FileState) bool {
+	from, ok := fileStateRanks[s]
+	to, nextOk := fileStateRanks[next]
+	if !ok || !nextOk || s == next {
+		return true
+	}
+
+	if s.IsTerminal() {
+		return (s == FileStateFailed || s == FileStateCancelled) && (next == FileStatePending || next == FileStateWaiting)
+	}
+
+	return to > from
+}
@@ -0,0 +1,90 @@
+package zencoder
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestJobState(t *testing.T) {
+	terminal := map[JobState]bool{
+		JobStatePending:    false,
+		JobStateWaiting:    false,
+		JobStateProcessing: false,
+		JobStateFinished:   true,
+		JobStateFailed:     true,
+		JobStateCancelled:  true,
+		"archived":         false,
+	}
+
+	for state, expected := range terminal {
+		if state.IsTerminal() != expected {
+			t.Fatal("Unexpected IsTerminal", state)
+		}
+	}
+
+	if !JobStateFinished.IsSuccess() || JobStateFailed.IsSuccess() {
+		t.Fatal("Expected only finished jobs to succeed")
+	}
+
+	transitions := []struct {
+		from, to JobState
+		legal    bool
+	}{
+		{JobStatePending, JobStateProcessing, true},
+		{JobStateWaiting, JobStateCancelled, true},
+		{JobStateProcessing, JobStateProcessing, true},
+		{JobStateProcessing, JobStateWaiting, false},
+		{JobStateFinished, JobStateFailed, false},
+		{JobStateFinished, JobStateWaiting, false},
+		{JobStateFailed, JobStateWaiting, true},
+		{JobStateCancelled, JobStateProcessing, false},
+		{JobStateFinished, "archived", true},
+	}
+
+	for _, test := range transitions {
+		if test.from.CanTransitionTo(test.to) != test.legal {
+			t.Fatal("Unexpected CanTransitionTo", test.from, test.to)
+		}
+	}
+}
+
+func TestFileState(t *testing.T) {
+	for _, state := range []FileState{FileStateNoInput, FileStateSkipped, FileStateFailed} {
+		if !state.IsTerminal() || state.IsSuccess() {
+			t.Fatal("Expected a terminal failure", state)
+		}
+	}
+
+	if FileStateQueued.IsTerminal() || !FileStateQueued.CanTransitionTo(FileStateAssigning) || FileStateAssigning.CanTransitionTo(FileStateQueued) {
+		t.Fatal("Unexpected queued state")
+	}
+
+	if !FileStateFailed.CanTransitionTo(FileStatePending) || FileStateSkipped.CanTransitionTo(FileStatePending) {
+		t.Fatal("Expected only failed and cancelled files to be resubmitted")
+	}
+}
+
+func TestUnknownStates(t *testing.T) {
+	var progress JobProgress
+	if err := json.Unmarshal([]byte(`{"state": "archived", "outputs": [{"id": 1, "state": "transcribing"}]}`), &progress); err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if progress.State != "archived" || progress.State.IsKnown() || progress.State.IsTerminal() {
+		t.Fatal("Expected the unknown job state to be kept", progress.State)
+	}
+
+	output := progress.OutputProgress[0]
+	if output.State != "transcribing" || output.State.IsKnown() {
+		t.Fatal("Expected the unknown file state to be kept", output.State)
+	}
+
+	b, _ := json.Marshal(&progress)
+	if string(b) != `{"state":"archived","outputs":[{"id":1,"state":"transcribing"}]}` {
+		t.Fatal("Expected unknown states to round-trip", string(b))
+	}
+
+	if !JobStateProcessing.IsKnown() || !FileStateNoInput.IsKnown() {
+		t.Fatal("Expected documented states to be known")
+	}
+}
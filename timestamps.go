@@ -0,0 +1,99 @@
+package zencoder
+
+import (
+	"time"
+)
+
+// Timestamp is a time as formatted by the Zencoder API, e.g.
+// "2013-05-21T17:45:03Z".  It keeps the API's text, so that it is
+// re-marshalled unchanged.
+type Timestamp string
+
+// NewTimestamp formats t as the Zencoder API does
+func NewTimestamp(t time.Time) Timestamp {
+	return Timestamp(t.UTC().Format(time.RFC3339))
+}
+
+// Parse parses the timestamp
+func (t Timestamp) Parse() (time.Time, error) {
+	return time.Parse(time.RFC3339, string(t))
+}
+
+// Time returns the parsed timestamp, or the zero time if it is empty or invalid
+func (t Timestamp) Time() time.Time {
+	parsed, _ := t.Parse()
+	return parsed
+}
+
+// IsZero reports whether the timestamp is empty
+func (t Timestamp) IsZero() bool {
+	return t == ""
+}
+
+// since returns the time elapsed between two timestamps, if both are valid
+func since(from, to Timestamp) (time.Duration, bool) {
+	start, err := from.Parse()
+	if err != nil {
+		return 0, false
+	}
+
+	end, err := to.Parse()
+	if err != nil {
+		return 0, false
+	}
+
+	return end.Sub(start), true
+}
+
+// submitted returns when the job was submitted for encoding, or created if unknown
+func (j *Job) submitted() Timestamp {
+	if !j.SubmittedAt.IsZero() {
+		return j.SubmittedAt
+	}
+
+	return j.CreatedAt
+}
+
+// QueueTime returns the time from the job's creation to its submission for
+// encoding.  It is unknown while the job has not been submitted.
+func (j *Job) QueueTime() (time.Duration, bool) {
+	return since(j.CreatedAt, j.SubmittedAt)
+}
+
+// ProcessingTime returns the time from the job's submission for encoding to
+// its completion.  It is unknown until the job finishes, fails or is
+// cancelled; a job that never reports its submission, e.g. one cancelled
+// while queued, counts from its creation.
+func (j *Job) ProcessingTime() (time.Duration, bool) {
+	if !j.State.IsTerminal() {
+		return 0, false
+	}
+
+	return since(j.submitted(), j.FinishedAt)
+}
+
+// TotalTime returns the time from the job's creation to its completion
+func (j *Job) TotalTime() (time.Duration, bool) {
+	if !j.State.IsTerminal() {
+		return 0, false
+	}
+
+	return since(j.CreatedAt, j.FinishedAt)
+}
+
+// OutputCompletionTimes returns the time from the job's submission for
+// encoding, or its creation if unknown, to the completion of each finished output
+func (j *Job) OutputCompletionTimes() map[OutputID]time.Duration {
+	times := make(map[OutputID]time.Duration)
+	for _, output := range j.OutputMediaFiles {
+		if output == nil || !output.State.IsSuccess() {
+			continue
+		}
+
+		if d, ok := since(j.submitted(), output.FinishedAt); ok {
+			times[output.OutputID()] = d
+		}
+	}
+
+	return times
+}
@@ -0,0 +1,88 @@
+package zencoder
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestTimestamp(t *testing.T) {
+	ts := Timestamp("2013-05-21T17:45:03-07:00")
+	if !ts.Time().Equal(time.Date(2013, 5, 22, 0, 45, 3, 0, time.UTC)) {
+		t.Fatal("Unexpected time", ts.Time())
+	}
+
+	if _, err := Timestamp("yesterday").Parse(); err == nil {
+		t.Fatal("Expected error")
+	}
+
+	if !Timestamp("").Time().IsZero() || !Timestamp("").IsZero() {
+		t.Fatal("Expected the zero time for an empty timestamp")
+	}
+
+	if ts := NewTimestamp(time.Date(2013, 5, 21, 17, 45, 3, 0, time.UTC)); ts != "2013-05-21T17:45:03Z" {
+		t.Fatal("Unexpected format", ts)
+	}
+
+	// Timestamps round-trip unchanged
+	var job Job
+	raw := `{"created_at":"2013-05-21T17:45:03.123-07:00"}`
+	if err := json.Unmarshal([]byte(raw), &job); err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if job.CreatedAt.Time().Nanosecond() != 123000000 {
+		t.Fatal("Expected fractional seconds", job.CreatedAt.Time())
+	}
+
+	if b, _ := json.Marshal(&job); string(b) != raw {
+		t.Fatal("Expected the timestamp unchanged", string(b))
+	}
+}
+
+func TestJobTimings(t *testing.T) {
+	job := &Job{
+		State:       JobStateFinished,
+		CreatedAt:   "2013-05-21T17:45:00Z",
+		SubmittedAt: "2013-05-21T17:45:10Z",
+		FinishedAt:  "2013-05-21T17:50:10Z",
+		OutputMediaFiles: []*MediaFile{
+			{Id: 1, State: FileStateFinished, FinishedAt: "2013-05-21T17:47:10Z"},
+			{Id: 2, State: FileStateFinished, FinishedAt: "2013-05-21T17:50:10Z"},
+			{Id: 3, State: FileStateFailed, FinishedAt: "2013-05-21T17:46:10Z"},
+		},
+	}
+
+	if d, ok := job.QueueTime(); !ok || d != 10*time.Second {
+		t.Fatal("Unexpected queue time", d)
+	}
+
+	if d, ok := job.ProcessingTime(); !ok || d != 5*time.Minute {
+		t.Fatal("Unexpected processing time", d)
+	}
+
+	if d, ok := job.TotalTime(); !ok || d != 5*time.Minute+10*time.Second {
+		t.Fatal("Unexpected total time", d)
+	}
+
+	times := job.OutputCompletionTimes()
+	if len(times) != 2 || times[1] != 2*time.Minute || times[2] != 5*time.Minute {
+		t.Fatal("Unexpected output completion times", times)
+	}
+
+	// Without a submission time, processing counts from the job's creation
+	// and the queue time is unknown
+	job.SubmittedAt = ""
+	if d, _ := job.ProcessingTime(); d != 5*time.Minute+10*time.Second {
+		t.Fatal("Unexpected processing time", d)
+	}
+
+	if _, ok := job.QueueTime(); ok {
+		t.Fatal("Expected no queue time for a job not submitted")
+	}
+
+	job.State = JobStateProcessing
+	if _, ok := job.ProcessingTime(); ok {
+		t.Fatal("Expected no processing time for an unfinished job")
+	}
+}
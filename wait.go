@@ -0,0 +1,131 @@
+package zencoder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+var (
+	ErrJobFailed    = errors.New("zencoder: job failed")
+	ErrJobCancelled = errors.New("zencoder: job cancelled")
+)
+
+// JobFailedError is returned by WaitForJob when a job fails or is cancelled.
+// It matches ErrJobFailed or ErrJobCancelled through errors.Is.
+type JobFailedError struct {
+	Job    *Job              // Final details of the job
+	Errors []*MediaFileError // Errors of its input and outputs
+}
+
+func (e *JobFailedError) Error() string {
+	msg := fmt.Sprintf("zencoder: job %d %s", e.Job.Id, e.Job.State)
+	for _, err := range e.Errors {
+		if err.ErrorMessage != nil {
+			return msg + ": " + *err.ErrorMessage
+		}
+	}
+
+	return msg
+}
+
+func (e *JobFailedError) Is(target error) bool {
+	switch target {
+	case ErrJobFailed:
+		return e.Job.State == JobStateFailed
+	case ErrJobCancelled:
+		return e.Job.State == JobStateCancelled
+	}
+
+	return false
+}
+
+// WaitOptions configures WaitForJob
+type WaitOptions struct {
+	MinInterval time.Duration // Polling interval while the job progresses, 1s if zero
+	MaxInterval time.Duration // Upper bound of the interval while it does not, 30s if zero
+
+	// OnProgress is called with the first progress and then on every change,
+	// whether of state, current event or input or output progress
+	OnProgress func(progress *JobProgress)
+}
+
+// WaitForJob polls a job's progress until it finishes, fails or is cancelled,
+// and returns its final details.
+//
+// Polling starts at MinInterval and doubles, up to MaxInterval, for as long as
+// the progress does not change.  If the job fails or is cancelled, its final
+// details are returned along with a *JobFailedError carrying the errors of its
+// media files.
+func (z *Zencoder) WaitForJob(ctx context.Context, id JobID, opts *WaitOptions) (*JobDetails, error) {
+	var options WaitOptions
+	if opts != nil {
+		options = *opts
+	}
+
+	if options.MinInterval <= 0 {
+		options.MinInterval = time.Second
+	}
+
+	if options.MaxInterval <= 0 {
+		options.MaxInterval = 30 * time.Second
+	}
+
+	if options.MaxInterval < options.MinInterval {
+		options.MaxInterval = options.MinInterval
+	}
+
+	var last *JobProgress
+	interval := options.MinInterval
+	for {
+		progress, err := z.GetJobProgressContext(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		if last == nil || !reflect.DeepEqual(last, progress) {
+			if options.OnProgress != nil {
+				options.OnProgress(progress)
+			}
+			interval = options.MinInterval
+		} else {
+			interval = min(2*interval, options.MaxInterval)
+		}
+		last = progress
+
+		if progress.State.IsTerminal() {
+			return z.finalDetails(ctx, id)
+		}
+
+		if err := sleep(ctx, interval); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// finalDetails returns the details of a job in a terminal state, with a
+// *JobFailedError if it failed or was cancelled
+func (z *Zencoder) finalDetails(ctx context.Context, id JobID) (*JobDetails, error) {
+	details, err := z.GetJobDetailsContext(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	job := details.Job
+	if job == nil || !job.State.IsTerminal() || job.State.IsSuccess() {
+		return details, nil
+	}
+
+	failure := &JobFailedError{Job: job}
+	if job.InputMediaFile != nil {
+		failure.Errors = append(failure.Errors, job.InputMediaFile.Errors()...)
+	}
+
+	for _, output := range job.OutputMediaFiles {
+		failure.Errors = append(failure.Errors, output.Errors()...)
+	}
+
+	return details, failure
+}
@@ -0,0 +1,123 @@
+package zencoder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWaitForJob(t *testing.T) {
+	polls := []string{
+		`{"state": "waiting"}`,
+		`{"state": "processing", "progress": 10, "input": {"state": "processing", "current_event": "Downloading"}}`,
+		`{"state": "processing", "progress": 10, "input": {"state": "processing", "current_event": "Downloading"}}`,
+		`{"state": "processing", "progress": 60, "outputs": [{"id": 456, "state": "processing", "progress": 20}]}`,
+		`{"state": "finished", "progress": 100}`,
+	}
+	poll := 0
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jobs/123/progress.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, polls[poll])
+		poll++
+	})
+	mux.HandleFunc("/jobs/123.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"job": {"id": 123, "state": "finished"}}`)
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	zc := NewZencoder("abc", WithBaseURL(srv.URL))
+
+	var states []string
+	details, err := zc.WaitForJob(context.Background(), 123, &WaitOptions{
+		MinInterval: time.Millisecond,
+		MaxInterval: 2 * time.Millisecond,
+		OnProgress: func(progress *JobProgress) {
+			states = append(states, fmt.Sprint(progress.State, " ", progress.JobProgress))
+		},
+	})
+	if err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if details.Job.State != "finished" {
+		t.Fatal("Expected the final details", details.Job)
+	}
+
+	if poll != len(polls) {
+		t.Fatal("Expected every poll", poll)
+	}
+
+	expected := []string{"waiting 0", "processing 10", "processing 60", "finished 100"}
+	if fmt.Sprint(states) != fmt.Sprint(expected) {
+		t.Fatal("Expected a callback per change", states)
+	}
+}
+
+func TestWaitForJobFailed(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jobs/123/progress.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"state": "failed"}`)
+	})
+	mux.HandleFunc("/jobs/123.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"job": {"id": 123, "state": "failed",
+  "input_media_file": {"id": 1, "state": "finished"},
+  "output_media_files": [
+    {"id": 2, "state": "failed", "error_class": "FileNotFoundError", "error_message": "The file is an invalid format"},
+    {"id": 3, "state": "finished"}
+  ]}}`)
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	zc := NewZencoder("abc", WithBaseURL(srv.URL))
+
+	details, err := zc.WaitForJob(context.Background(), 123, nil)
+	if !errors.Is(err, ErrJobFailed) || errors.Is(err, ErrJobCancelled) {
+		t.Fatal("Expected ErrJobFailed", err)
+	}
+
+	var failure *JobFailedError
+	if !errors.As(err, &failure) {
+		t.Fatal("Expected a JobFailedError", err)
+	}
+
+	if len(failure.Errors) != 1 || failure.Errors[0].Id != 2 || *failure.Errors[0].ErrorClass != "FileNotFoundError" {
+		t.Fatal("Expected the output's errors", failure.Errors)
+	}
+
+	if err.Error() != "zencoder: job 123 failed: The file is an invalid format" {
+		t.Fatal("Unexpected message", err.Error())
+	}
+
+	if details == nil || details.Job.Id != 123 {
+		t.Fatal("Expected the final details", details)
+	}
+}
+
+func TestWaitForJobContext(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jobs/123/progress.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"state": "processing"}`)
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	zc := NewZencoder("abc", WithBaseURL(srv.URL))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := zc.WaitForJob(ctx, 123, &WaitOptions{MinInterval: time.Millisecond})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal("Expected context.DeadlineExceeded", err)
+	}
+}
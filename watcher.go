@@ -0,0 +1,420 @@
+package zencoder
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+)
+
+// WatchEvent is an event emitted by a Watcher: a JobStateChanged,
+// OutputStateChanged, ProgressUpdated, JobFailed or WatchError
+type WatchEvent interface {
+	JobID() JobID
+}
+
+// JobStateChanged is emitted when a job is first polled and when its state changes
+type JobStateChanged struct {
+	Id   JobID
+	From JobState // Previous state, empty when the job is first polled
+	To   JobState
+}
+
+// OutputStateChanged is emitted when an output is first seen and when its state changes
+type OutputStateChanged struct {
+	Id     JobID
+	Output OutputID
+	From   FileState // Previous state, empty when the output is first seen
+	To     FileState
+}
+
+// ProgressUpdated is emitted whenever the progress of a job changes
+type ProgressUpdated struct {
+	Id       JobID
+	Progress *JobProgress
+}
+
+// JobFailed is emitted when a job fails or is cancelled, after its JobStateChanged
+type JobFailed struct {
+	Id  JobID
+	Err *JobFailedError
+}
+
+// WatchError is emitted when a job cannot be polled.  The job is still
+// watched, unless the error is ErrNotFound.
+type WatchError struct {
+	Id  JobID
+	Err error
+}
+
+func (e JobStateChanged) JobID() JobID    { return e.Id }
+func (e OutputStateChanged) JobID() JobID { return e.Id }
+func (e ProgressUpdated) JobID() JobID    { return e.Id }
+func (e JobFailed) JobID() JobID          { return e.Id }
+func (e WatchError) JobID() JobID         { return e.Id }
+
+// WatcherOptions configures a Watcher
+type WatcherOptions struct {
+	Interval time.Duration // Time between two polls of a job, 10s if zero
+	Limit    Limit         // Budget shared by all polls; at most 4 in flight if MaxInFlight is zero
+	Buffer   int           // Capacity of the events channel, 100 if zero
+}
+
+// Watcher polls the progress of many jobs and emits their state transitions
+// as events.
+//
+// Jobs are added and removed at any time, and polled every Interval within a
+// budget shared by all of them.  A job is no longer watched once it has
+// finished, failed or been cancelled.  Events must be received until the
+// channel is closed, which happens after Close or when the context given to
+// NewWatcher is done.  IDs returns the jobs still watched, so that a new
+// Watcher can resume with them.  A Watcher is safe for concurrent use.
+type Watcher struct {
+	z       *Zencoder
+	options WatcherOptions
+	budget  *budget
+	events  chan WatchEvent
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	stop   chan struct{}
+	wake   chan struct{}
+	wg     sync.WaitGroup
+	close  sync.Once
+
+	mu       sync.Mutex
+	jobs     map[JobID]*watchedJob
+	schedule watchSchedule
+	closed   bool
+}
+
+type watchedJob struct {
+	id    JobID
+	next  time.Time
+	index int // Position in the schedule, -1 while being polled
+	last  *JobProgress
+}
+
+// NewWatcher returns a Watcher polling with z.  Cancelling ctx stops it
+// immediately, aborting requests in flight.
+func NewWatcher(ctx context.Context, z *Zencoder, opts *WatcherOptions) *Watcher {
+	var options WatcherOptions
+	if opts != nil {
+		options = *opts
+	}
+
+	if options.Interval <= 0 {
+		options.Interval = 10 * time.Second
+	}
+
+	if options.Limit.MaxInFlight <= 0 {
+		options.Limit.MaxInFlight = 4
+	}
+
+	if options.Buffer <= 0 {
+		options.Buffer = 100
+	}
+
+	w := &Watcher{
+		z:       z,
+		options: options,
+		budget:  newBudget(options.Limit),
+		events:  make(chan WatchEvent, options.Buffer),
+		stop:    make(chan struct{}),
+		wake:    make(chan struct{}, 1),
+		jobs:    make(map[JobID]*watchedJob),
+	}
+	w.ctx, w.cancel = context.WithCancel(ctx)
+
+	w.wg.Add(1)
+	go w.run()
+
+	go func() {
+		w.wg.Wait()
+		w.cancel()
+		close(w.events)
+	}()
+
+	return w
+}
+
+// Events returns the channel on which events are emitted
+func (w *Watcher) Events() <-chan WatchEvent {
+	return w.events
+}
+
+// Add starts watching jobs; jobs already watched are left as they are
+func (w *Watcher) Add(ids ...JobID) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return
+	}
+
+	now := time.Now()
+	for _, id := range ids {
+		if _, ok := w.jobs[id]; ok {
+			continue
+		}
+
+		job := &watchedJob{id: id, next: now}
+		w.jobs[id] = job
+		heap.Push(&w.schedule, job)
+	}
+
+	w.signal()
+}
+
+// Remove stops watching jobs
+func (w *Watcher) Remove(ids ...JobID) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, id := range ids {
+		w.remove(id)
+	}
+}
+
+// IDs returns the jobs being watched, in ascending order
+func (w *Watcher) IDs() []JobID {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	ids := make([]JobID, 0, len(w.jobs))
+	for id := range w.jobs {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	return ids
+}
+
+// Close stops polling, waits for the polls in flight to emit their events and
+// closes the events channel
+func (w *Watcher) Close() error {
+	w.close.Do(func() {
+		w.mu.Lock()
+		w.closed = true
+		w.mu.Unlock()
+
+		close(w.stop)
+	})
+
+	w.wg.Wait()
+	return nil
+}
+
+// remove stops watching a job.  w.mu must be held.
+func (w *Watcher) remove(id JobID) {
+	job, ok := w.jobs[id]
+	if !ok {
+		return
+	}
+
+	delete(w.jobs, id)
+	if job.index >= 0 {
+		heap.Remove(&w.schedule, job.index)
+	}
+}
+
+// signal wakes up the scheduler.  w.mu must be held.
+func (w *Watcher) signal() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run dispatches the polls of jobs as they fall due
+func (w *Watcher) run() {
+	defer w.wg.Done()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-w.ctx.Done():
+			return
+		default:
+		}
+
+		job, wait := w.due()
+		if job != nil {
+			release, err := w.budget.acquire(w.ctx)
+			if err != nil {
+				return
+			}
+
+			w.wg.Add(1)
+			go func() {
+				defer w.wg.Done()
+				defer release()
+				w.poll(job)
+			}()
+			continue
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-w.wake:
+		case <-w.stop:
+		case <-w.ctx.Done():
+		}
+		timer.Stop()
+	}
+}
+
+// due takes the next job due for a poll off the schedule, or returns how
+// long to wait for one
+func (w *Watcher) due() (*watchedJob, time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.schedule) == 0 {
+		return nil, time.Hour
+	}
+
+	job := w.schedule[0]
+	if wait := time.Until(job.next); wait > 0 {
+		return nil, wait
+	}
+
+	heap.Pop(&w.schedule)
+	return job, 0
+}
+
+// reschedule puts a polled job back on the schedule, unless it was removed
+func (w *Watcher) reschedule(job *watchedJob) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.jobs[job.id] != job {
+		return
+	}
+
+	job.next = time.Now().Add(w.options.Interval)
+	heap.Push(&w.schedule, job)
+	w.signal()
+}
+
+// forget stops watching a polled job
+func (w *Watcher) forget(job *watchedJob) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.jobs[job.id] == job {
+		delete(w.jobs, job.id)
+	}
+}
+
+func (w *Watcher) emit(event WatchEvent) {
+	select {
+	case w.events <- event:
+	case <-w.ctx.Done():
+	}
+}
+
+// poll polls a job and emits the events for what changed since the last poll
+func (w *Watcher) poll(job *watchedJob) {
+	progress, err := w.z.GetJobProgressContext(w.ctx, job.id)
+	if err != nil {
+		if w.ctx.Err() != nil {
+			return
+		}
+
+		w.emit(WatchError{Id: job.id, Err: err})
+		if errors.Is(err, ErrNotFound) {
+			w.forget(job)
+		} else {
+			w.reschedule(job)
+		}
+		return
+	}
+
+	for _, event := range progressEvents(job.id, job.last, progress) {
+		w.emit(event)
+	}
+	job.last = progress
+
+	if !progress.State.IsTerminal() {
+		w.reschedule(job)
+		return
+	}
+
+	_, err = w.z.finalDetails(w.ctx, job.id)
+	var failure *JobFailedError
+	switch {
+	case errors.As(err, &failure):
+		w.emit(JobFailed{Id: job.id, Err: failure})
+	case err != nil:
+		if w.ctx.Err() != nil {
+			return
+		}
+
+		// Try again on the next poll
+		w.emit(WatchError{Id: job.id, Err: err})
+		w.reschedule(job)
+		return
+	}
+
+	w.forget(job)
+}
+
+// progressEvents returns the events for a change from last to progress
+func progressEvents(id JobID, last, progress *JobProgress) []WatchEvent {
+	if last == nil {
+		last = &JobProgress{}
+	} else if reflect.DeepEqual(last, progress) {
+		return nil
+	}
+
+	var events []WatchEvent
+	if progress.State != last.State {
+		events = append(events, JobStateChanged{Id: id, From: last.State, To: progress.State})
+	}
+
+	states := make(map[int64]FileState, len(last.OutputProgress))
+	for _, output := range last.OutputProgress {
+		states[output.Id] = output.State
+	}
+
+	for _, output := range progress.OutputProgress {
+		if from := states[output.Id]; from != output.State {
+			events = append(events, OutputStateChanged{Id: id, Output: output.OutputID(), From: from, To: output.State})
+		}
+	}
+
+	return append(events, ProgressUpdated{Id: id, Progress: progress})
+}
+
+// watchSchedule is a heap of jobs ordered by the time of their next poll
+type watchSchedule []*watchedJob
+
+func (s watchSchedule) Len() int           { return len(s) }
+func (s watchSchedule) Less(i, j int) bool { return s[i].next.Before(s[j].next) }
+
+func (s watchSchedule) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+	s[i].index = i
+	s[j].index = j
+}
+
+func (s *watchSchedule) Push(x interface{}) {
+	job := x.(*watchedJob)
+	job.index = len(*s)
+	*s = append(*s, job)
+}
+
+func (s *watchSchedule) Pop() interface{} {
+	old := *s
+	job := old[len(old)-1]
+	old[len(old)-1] = nil
+	job.index = -1
+	*s = old[:len(old)-1]
+	return job
+}
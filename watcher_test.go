@@ -0,0 +1,186 @@
+package zencoder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// scriptedJobsServer serves scripted progress for jobs, repeating the last entry
+type scriptedJobsServer struct {
+	mu       sync.Mutex
+	progress map[string][]string
+	details  map[string]string
+}
+
+func (s *scriptedJobsServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	for id := range s.progress {
+		id := id
+		mux.HandleFunc("/jobs/"+id+"/progress.json", func(w http.ResponseWriter, r *http.Request) {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+
+			script := s.progress[id]
+			fmt.Fprintln(w, script[0])
+			if len(script) > 1 {
+				s.progress[id] = script[1:]
+			}
+		})
+		mux.HandleFunc("/jobs/"+id+".json", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, s.details[id])
+		})
+	}
+
+	return mux
+}
+
+// nextEvent receives an event, failing the test if none comes in time
+func nextEvent(t *testing.T, watcher *Watcher) (WatchEvent, bool) {
+	select {
+	case event, ok := <-watcher.Events():
+		return event, ok
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected an event")
+		return nil, false
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	s := &scriptedJobsServer{
+		progress: map[string][]string{
+			"1": {
+				`{"state": "processing", "progress": 10, "outputs": [{"id": 11, "state": "queued"}]}`,
+				`{"state": "processing", "progress": 10, "outputs": [{"id": 11, "state": "queued"}]}`,
+				`{"state": "processing", "progress": 50, "outputs": [{"id": 11, "state": "processing", "progress": 20}]}`,
+				`{"state": "finished", "progress": 100, "outputs": [{"id": 11, "state": "finished", "progress": 100}]}`,
+			},
+			"2": {
+				`{"state": "processing", "progress": 30}`,
+				`{"state": "failed", "progress": 30}`,
+			},
+		},
+		details: map[string]string{
+			"1": `{"job": {"id": 1, "state": "finished"}}`,
+			"2": `{"job": {"id": 2, "state": "failed", "output_media_files": [{"id": 21, "state": "failed", "error_class": "UnknownError"}]}}`,
+		},
+	}
+
+	srv := httptest.NewServer(s.handler())
+	defer srv.Close()
+
+	zc := NewZencoder("abc", WithBaseURL(srv.URL))
+	watcher := NewWatcher(context.Background(), zc, &WatcherOptions{Interval: time.Millisecond, Limit: Limit{MaxInFlight: 2}})
+	watcher.Add(1, 2, 3)
+
+	// Close once every job has reached a terminal state
+	go func() {
+		for len(watcher.IDs()) > 0 {
+			time.Sleep(time.Millisecond)
+		}
+		watcher.Close()
+	}()
+
+	events := map[JobID][]string{}
+	for {
+		event, ok := nextEvent(t, watcher)
+		if !ok {
+			break
+		}
+
+		var description string
+		switch e := event.(type) {
+		case JobStateChanged:
+			description = fmt.Sprintf("job %s->%s", e.From, e.To)
+		case OutputStateChanged:
+			description = fmt.Sprintf("output %d %s->%s", e.Output, e.From, e.To)
+		case ProgressUpdated:
+			description = fmt.Sprint("progress ", e.Progress.JobProgress)
+		case JobFailed:
+			description = fmt.Sprint("failed ", len(e.Err.Errors))
+		case WatchError:
+			description = fmt.Sprint("error ", errors.Is(e.Err, ErrNotFound))
+		}
+		events[event.JobID()] = append(events[event.JobID()], description)
+	}
+
+	expected := map[JobID][]string{
+		1: {
+			"job ->processing", "output 11 ->queued", "progress 10",
+			"output 11 queued->processing", "progress 50",
+			"job processing->finished", "output 11 processing->finished", "progress 100",
+		},
+		2: {
+			"job ->processing", "progress 30",
+			"job processing->failed", "progress 30", "failed 1",
+		},
+		3: {"error true"},
+	}
+
+	if fmt.Sprint(events) != fmt.Sprint(expected) {
+		t.Fatal("Unexpected events", events)
+	}
+}
+
+func TestWatcherResume(t *testing.T) {
+	s := &scriptedJobsServer{
+		progress: map[string][]string{
+			"1": {`{"state": "processing"}`},
+			"2": {`{"state": "processing"}`},
+		},
+	}
+
+	srv := httptest.NewServer(s.handler())
+	defer srv.Close()
+
+	zc := NewZencoder("abc", WithBaseURL(srv.URL))
+	watcher := NewWatcher(context.Background(), zc, &WatcherOptions{Interval: time.Hour})
+	watcher.Add(2, 1, 2)
+
+	// Both jobs are polled once, then wait for the next interval
+	for i := 0; i < 4; i++ {
+		nextEvent(t, watcher)
+	}
+
+	watcher.Remove(2)
+	if err := watcher.Close(); err != nil {
+		t.Fatal("Expected no error", err)
+	}
+
+	if _, ok := nextEvent(t, watcher); ok {
+		t.Fatal("Expected the events channel to be closed")
+	}
+
+	saved := watcher.IDs()
+	if len(saved) != 1 || saved[0] != 1 {
+		t.Fatal("Expected the jobs still watched", saved)
+	}
+
+	watcher.Add(3)
+	if len(watcher.IDs()) != 1 {
+		t.Fatal("Expected no jobs to be added after Close")
+	}
+
+	// A new watcher resumes from the saved jobs
+	ctx, cancel := context.WithCancel(context.Background())
+	resumed := NewWatcher(ctx, zc, nil)
+	resumed.Add(saved...)
+
+	event, _ := nextEvent(t, resumed)
+	if e, ok := event.(JobStateChanged); !ok || e.Id != 1 || e.To != "processing" {
+		t.Fatal("Expected the current state of the resumed job", event)
+	}
+
+	// Cancelling the context stops the watcher
+	cancel()
+	for {
+		if _, ok := nextEvent(t, resumed); !ok {
+			break
+		}
+	}
+}
@@ -2,51 +2,115 @@ package zencoder
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
-	"io/ioutil"
+	"log/slog"
 	"net/http"
+	"time"
 )
 
+// Zencoder is a client for the Zencoder API.
+//
+// A Zencoder is safe for concurrent use by multiple goroutines once it has
+// been configured; its fields must not be modified while requests are in
+// flight.  Use ContextWithHeader or ContextWithAPIKey to vary headers per call.
 type Zencoder struct {
-	BaseUrl string
-	Header  http.Header
-	Client  *http.Client
+	BaseUrl  string
+	Header   http.Header
+	Client   *http.Client
+	Retry    *RetryPolicy // Retry policy for transient failures, nil disables retries
+	Governor *Governor    // Rate and concurrency limits, nil for unlimited
+	Logger   *slog.Logger // Diagnostic logger, nil disables logging
+	Hooks    []Hook       // Hooks run around every request, in order
+	Breaker  *Breaker     // Circuit breaker, nil disables it
+	DryRun   *DryRun      // Records mutating calls instead of sending them, nil sends them
+	Cache    *Cache       // Cache of terminal job and media file details, nil disables it
+
+	// Coalescer collapses identical in-flight GET requests, nil disables it
+	Coalescer *Coalescer
+
+	// MaxResponseSize bounds decoded response bodies, in bytes; 0 for unlimited
+	MaxResponseSize int64
+
+	// OnUnknownFields enables strict decoding: it is called with the dotted
+	// paths of response fields this package does not know about
+	OnUnknownFields func(path string, fields []string)
 }
 
-func NewZencoder(apiKey string) *Zencoder {
-	return &Zencoder{
-		Client:  http.DefaultClient,
-		BaseUrl: "https://app.zencoder.com/api/v2/",
+const (
+	DefaultBaseURL   = "https://app.zencoder.com/api/v2/"
+	DefaultUserAgent = "gozencoder v1"
+	DefaultTimeout   = 60 * time.Second
+)
+
+// NewZencoder returns a client bound to apiKey, configured by opts
+func NewZencoder(apiKey string, opts ...Option) *Zencoder {
+	z := &Zencoder{
+		Client:          &http.Client{Timeout: DefaultTimeout},
+		BaseUrl:         DefaultBaseURL,
+		MaxResponseSize: DefaultMaxResponseSize,
 		Header: http.Header{
 			"Content-Type":     []string{"application/json"},
 			"Accept":           []string{"application/json"},
 			"Zencoder-Api-Key": []string{apiKey},
-			"User-Agent":       []string{"gozencoder v1"},
+			"User-Agent":       []string{DefaultUserAgent},
 		},
 	}
+
+	for _, opt := range opts {
+		opt(z)
+	}
+
+	return z
 }
 
-func (z *Zencoder) call(method, path string, request interface{}, expectedStatus []int) (*http.Response, error) {
-	var buffer io.Reader
+func (z *Zencoder) call(ctx context.Context, method, path string, request interface{}, expectedStatus []int) (*http.Response, error) {
+	var body []byte
 	if request != nil {
 		b, err := json.Marshal(request)
 		if err != nil {
 			return nil, err
 		}
 
-		buffer = bytes.NewBuffer(b)
+		body = b
 	}
 
-	req, err := http.NewRequest(method, fmt.Sprintf("%s/%s", z.BaseUrl, path), buffer)
-	if err != nil {
-		return nil, err
-	}
+	var info *CallInfo
+	var resp *http.Response
+	var err error
+	for attempt := 1; ; attempt++ {
+		info = &CallInfo{
+			Method:  method,
+			Path:    path,
+			Class:   classify(method, path),
+			Payload: request,
+			Attempt: attempt,
+		}
 
-	req.Header = z.Header
+		resp, err = z.do(ctx, info, body)
+		if !z.Retry.shouldRetry(ctx, method, path, attempt, resp, err) {
+			break
+		}
+
+		wait := z.Retry.backoff(attempt, resp)
+		if z.Logger != nil {
+			z.Logger.LogAttrs(ctx, slog.LevelWarn, "zencoder: retrying request",
+				slog.String("method", method),
+				slog.String("path", path),
+				slog.Int("attempt", attempt),
+				slog.Duration("wait", wait))
+		}
+		if resp != nil {
+			drainAndClose(resp.Body)
+		}
+
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
 
-	resp, err := z.Client.Do(req)
 	if err != nil {
 		return resp, err
 	}
@@ -57,56 +121,164 @@ func (z *Zencoder) call(method, path string, request interface{}, expectedStatus
 		}
 	}
 
-	// Format the error
-	// If there is an unexpected status, format as status + body
-	bodyBytes, _ := ioutil.ReadAll(resp.Body)
-	err = fmt.Errorf("%s. Body: %s", resp.Status, string(bodyBytes))
+	// If there is an unexpected status, return an APIError carrying status + body
+	err = newAPIError(method, path, resp, readErrorBody(resp.Body))
+	z.onError(ctx, info, err)
 
 	return nil, err
 }
 
-func (z *Zencoder) post(path string, request interface{}, response interface{}) error {
-	resp, err := z.call("POST", path, request, []int{http.StatusCreated, http.StatusOK})
-	if err != nil {
-		return err
+// logCall logs one attempt of an API call with its secrets redacted
+func (z *Zencoder) logCall(ctx context.Context, info *CallInfo, err error) {
+	if z.Logger == nil {
+		return
 	}
 
-	if err := UnmarshalBody(resp.Body, response); err != nil {
-		return err
+	level := slog.LevelInfo
+	switch {
+	case err != nil:
+		level = slog.LevelError
+	case info.StatusCode >= 400:
+		level = slog.LevelWarn
+	}
+
+	if !z.Logger.Enabled(ctx, level) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", info.Method),
+		slog.String("path", info.Path),
+		slog.Int("attempt", info.Attempt),
+		slog.Int("status", info.StatusCode),
+		slog.Duration("latency", info.Latency),
+		slog.Any("header", RedactHeader(info.Request.Header)),
 	}
 
-	return nil
+	if info.Payload != nil {
+		attrs = append(attrs, slog.String("payload", redactPayload(info.Payload)))
+	}
+
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+
+	z.Logger.LogAttrs(ctx, level, "zencoder: api call", attrs...)
 }
 
-func (z *Zencoder) putNoContent(path string) error {
-	_, err := z.call("PUT", path, nil, []int{http.StatusNoContent})
+// do performs a single HTTP round trip, running the hooks around it
+func (z *Zencoder) do(ctx context.Context, info *CallInfo, body []byte) (*http.Response, error) {
+	var buffer io.Reader
+	if body != nil {
+		buffer = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, info.Method, fmt.Sprintf("%s/%s", z.BaseUrl, info.Path), buffer)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	req.Header = z.requestHeader(ctx)
+	info.Request = req
+
+	for _, hook := range z.Hooks {
+		if hook.BeforeRequest == nil {
+			continue
+		}
+
+		if err := hook.BeforeRequest(ctx, info); err != nil {
+			hookErr := &HookError{Err: err}
+			z.onError(ctx, info, hookErr)
+			return nil, hookErr
+		}
 	}
 
-	return nil
+	if z.DryRun.intercepts(info.Method) {
+		resp := z.DryRun.respond(info, body)
+		info.StatusCode = resp.StatusCode
+		z.logCall(ctx, info, nil)
+		z.afterResponse(ctx, info)
+		return resp, nil
+	}
+
+	release := func() {}
+	if z.Governor != nil {
+		release, err = z.Governor.acquire(ctx, info.Class)
+		if err != nil {
+			z.onError(ctx, info, err)
+			return nil, err
+		}
+	}
+
+	if z.Breaker != nil {
+		if err := z.Breaker.allow(info.Class); err != nil {
+			release()
+			z.onError(ctx, info, err)
+			return nil, err
+		}
+	}
+
+	start := time.Now()
+	resp, err := z.Client.Do(req)
+	info.Latency = time.Since(start)
+	if z.Breaker != nil {
+		var status int
+		if resp != nil {
+			status = resp.StatusCode
+		}
+		z.Breaker.record(ctx, info.Class, status, err)
+	}
+
+	if err != nil {
+		release()
+		z.logCall(ctx, info, err)
+		z.onError(ctx, info, err)
+		return resp, err
+	}
+
+	info.StatusCode = resp.StatusCode
+	z.logCall(ctx, info, nil)
+	z.afterResponse(ctx, info)
+
+	if z.Governor != nil {
+		resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: release}
+	}
+
+	return resp, nil
 }
 
-func (z *Zencoder) getBody(path string, response interface{}) error {
-	resp, err := z.call("GET", path, nil, []int{http.StatusOK})
+func (z *Zencoder) post(ctx context.Context, path string, request interface{}, response interface{}) error {
+	resp, err := z.call(ctx, "POST", path, request, []int{http.StatusCreated, http.StatusOK})
 	if err != nil {
 		return err
 	}
 
-	if err := UnmarshalBody(resp.Body, response); err != nil {
+	return z.decode(path, resp.Body, response)
+}
+
+func (z *Zencoder) putNoContent(ctx context.Context, path string) error {
+	resp, err := z.call(ctx, "PUT", path, nil, []int{http.StatusNoContent})
+	if err != nil {
 		return err
 	}
 
-	return nil
+	return drainAndClose(resp.Body)
 }
 
-func UnmarshalBody(body io.ReadCloser, result interface{}) error {
-	defer body.Close()
+func (z *Zencoder) getBody(ctx context.Context, path string, response interface{}) error {
+	if z.Coalescer != nil {
+		return z.getCoalesced(ctx, path, response)
+	}
 
-	b, err := ioutil.ReadAll(body)
+	resp, err := z.call(ctx, "GET", path, nil, []int{http.StatusOK})
 	if err != nil {
 		return err
 	}
 
-	return json.Unmarshal(b, result)
+	return z.decode(path, resp.Body, response)
+}
+
+// UnmarshalBody decodes a JSON body into result and closes it
+func UnmarshalBody(body io.ReadCloser, result interface{}) error {
+	return decodeBody(body, result, 0)
 }
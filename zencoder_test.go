@@ -1,9 +1,12 @@
 package zencoder
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestGenericCall(t *testing.T) {
@@ -19,7 +22,7 @@ func TestGenericCall(t *testing.T) {
 	zc := NewZencoder("abc")
 	zc.BaseUrl = srv.URL
 
-	resp, err := zc.call("GET", "test", nil, []int{http.StatusOK})
+	resp, err := zc.call(context.Background(), "GET", "test", nil, []int{http.StatusOK})
 	if err != nil {
 		t.Fatal("Expected no error", err)
 	}
@@ -64,3 +67,46 @@ func TestGenericCall(t *testing.T) {
 		t.Fatal("Expected Zencoder-Api-Key=abc", headers["Zencoder-Api-Key"])
 	}
 }
+
+func TestCallContextCancelled(t *testing.T) {
+	started := make(chan struct{})
+	aborted := make(chan struct{})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/test", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-r.Context().Done()
+		close(aborted)
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	zc := NewZencoder("abc")
+	zc.BaseUrl = srv.URL
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+
+	resp, err := zc.call(ctx, "GET", "test", nil, []int{http.StatusOK})
+	if err == nil {
+		t.Fatal("Expected error")
+	}
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatal("Expected context.Canceled", err)
+	}
+
+	if resp != nil {
+		t.Fatal("Expected no response")
+	}
+
+	select {
+	case <-aborted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the in-flight request to be aborted")
+	}
+}